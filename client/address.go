@@ -0,0 +1,97 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Address returns the resource address as printed by terraform, for example module.vpc.data.aws_region.current
+func (r Resource) Address() string {
+	address := r.Type + "." + r.Name
+	if r.Mode == "data" {
+		address = "data." + address
+	}
+	if r.Module != "" {
+		address = r.Module + "." + address
+	}
+	return address
+}
+
+// InstanceAddress returns the address of one of the resource instances, for example aws_subnet.private[0]
+func (r Resource) InstanceAddress(instance Instance) string {
+	return r.Address() + indexKeySuffix(instance.IndexKey)
+}
+
+// indexKeySuffix renders an instance index key the way terraform does in addresses, count indexes are
+// integers such as [0] and for_each keys quoted strings such as ["key"]
+func indexKeySuffix(key interface{}) string {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case string:
+		return "[" + quoteIndexKey(k) + "]"
+	case float64:
+		// index_key is decoded as a JSON number, count indexes are always integral
+		if k == math.Trunc(k) {
+			return fmt.Sprintf("[%d]", int64(k))
+		}
+		return fmt.Sprintf("[%v]", k)
+	case json.Number:
+		return "[" + k.String() + "]"
+	default:
+		return fmt.Sprintf("[%v]", k)
+	}
+}
+
+// quoteIndexKey quotes a string key with HCL's quoting syntax, as terraform's addrs.StringKey does
+func quoteIndexKey(s string) string {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '$', '%':
+			buf.WriteRune(r)
+			// template introducers are escaped by doubling the symbol
+			if strings.HasPrefix(s[i+1:], "{") {
+				buf.WriteRune(r)
+			}
+		default:
+			switch {
+			case unicode.IsPrint(r):
+				buf.WriteRune(r)
+			case r < 65536:
+				fmt.Fprintf(&buf, "\\u%04x", r)
+			default:
+				fmt.Fprintf(&buf, "\\U%08x", r)
+			}
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+// UniqueID derives a deterministic id of a resource instance from the backend name and instance address,
+// deposed objects of the instance get their own id
+func UniqueID(backendName string, address string, deposed string) string {
+	h := sha256.New()
+	for _, part := range []string{backendName, address, deposed} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
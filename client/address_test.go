@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceAddress(t *testing.T) {
+	assert.Equal(t, "aws_vpc.main", Resource{Mode: "managed", Type: "aws_vpc", Name: "main"}.Address())
+	assert.Equal(t, "data.aws_region.current", Resource{Mode: "data", Type: "aws_region", Name: "current"}.Address())
+	assert.Equal(t, "module.webapp.module.roles.aws_iam_role.task",
+		Resource{Module: "module.webapp.module.roles", Mode: "managed", Type: "aws_iam_role", Name: "task"}.Address())
+}
+
+func TestUniqueID(t *testing.T) {
+	id := UniqueID("prod", "aws_subnet.private[0]", "")
+	assert.Len(t, id, 64)
+	assert.Equal(t, id, UniqueID("prod", "aws_subnet.private[0]", ""))
+	assert.NotEqual(t, id, UniqueID("staging", "aws_subnet.private[0]", ""))
+	assert.NotEqual(t, id, UniqueID("prod", "aws_subnet.private[0]", "00000001"))
+	assert.NotEqual(t, UniqueID("ab", "c", ""), UniqueID("a", "bc", ""))
+}
+
+func TestInstanceAddress(t *testing.T) {
+	subnet := Resource{Module: "module.subnets", Mode: "managed", Type: "aws_subnet", Name: "private"}
+	user := Resource{Mode: "managed", Type: "aws_iam_user", Name: "this"}
+	for _, tc := range []struct {
+		resource Resource
+		key      interface{}
+		address  string
+	}{
+		{resource: user, key: nil, address: "aws_iam_user.this"},
+		// count
+		{resource: subnet, key: float64(0), address: "module.subnets.aws_subnet.private[0]"},
+		{resource: subnet, key: float64(12), address: "module.subnets.aws_subnet.private[12]"},
+		{resource: subnet, key: json.Number("3"), address: "module.subnets.aws_subnet.private[3]"},
+		// for_each
+		{resource: user, key: "admin", address: `aws_iam_user.this["admin"]`},
+		{resource: user, key: "0", address: `aws_iam_user.this["0"]`},
+		{resource: user, key: "", address: `aws_iam_user.this[""]`},
+		{resource: user, key: "foo.bar", address: `aws_iam_user.this["foo.bar"]`},
+		{resource: user, key: `say "hi"\now`, address: `aws_iam_user.this["say \"hi\"\\now"]`},
+		{resource: user, key: "a\tb\n", address: `aws_iam_user.this["a\tb\n"]`},
+		{resource: user, key: "${var}%{if}$x", address: `aws_iam_user.this["$${var}%%{if}$x"]`},
+		{resource: user, key: "caf\u00e9\u0007", address: `aws_iam_user.this["café\u0007"]`},
+	} {
+		assert.Equal(t, tc.address, tc.resource.InstanceAddress(Instance{IndexKey: tc.key}))
+	}
+}
+
+func TestInstanceAddressFromState(t *testing.T) {
+	f, err := os.Open("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	defer f.Close()
+	data, err := parseAndValidate(context.Background(), f, BackendOptions{})
+	require.NoError(t, err)
+
+	var addresses []string
+	for _, resource := range data.State.Resources {
+		for _, instance := range resource.Instances {
+			addresses = append(addresses, resource.InstanceAddress(instance))
+		}
+	}
+	assert.Subset(t, addresses, []string{
+		"data.terraform_remote_state.remote",
+		`module.logs.aws_cloudwatch_log_group.main["app"]`,
+		"aws_iam_role_policy_attachment.ec2[0]",
+		"aws_iam_role_policy_attachment.ec2[1]",
+		"module.webapp.module.ecs_task_roles.aws_iam_role.task_execution_role",
+		`aws_iam_user.users["foo.bar"]`,
+		`data.aws_lb_target_group.app["dev1"]`,
+	})
+}
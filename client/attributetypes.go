@@ -0,0 +1,124 @@
+package client
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// AttributeType is the type of an attribute of a resource type, inferred from the values of show -json
+type AttributeType struct {
+	Mode         string
+	ResourceType string
+	// AttributePath is the path of the attribute, elements of lists are denoted by [*], such as
+	// ebs_block_device[*].volume_size
+	AttributePath string
+	// Type is the Terraform type of the values: string, number, bool, list(<element type>), tuple or object.
+	// Maps, objects and nested blocks are all reported as object, lists and sets as list, list alone for lists
+	// which were always empty and tuple for lists of mixed element types.
+	Type string
+}
+
+// AttributeTypes infers the attribute types of the resource types from the resource values of show -json,
+// of the state or else of the planned state. Null values tell nothing about their type and are left out.
+func AttributeTypes(showJSON *ShowJSON) []AttributeType {
+	values := showJSON.Values
+	if values == nil {
+		values = showJSON.PlannedValues
+	}
+	if values == nil {
+		return nil
+	}
+
+	type typeKey struct{ mode, resourceType, path string }
+	types := make(map[typeKey]string)
+	var walkModule func(module ValuesModule)
+	walkModule = func(module ValuesModule) {
+		for _, resource := range module.Resources {
+			collectAttributeTypes(resource.Values, "", func(path, t string) {
+				key := typeKey{resource.Mode, resource.Type, path}
+				if existing, ok := types[key]; !ok || existing == "list" {
+					types[key] = t
+				}
+			})
+		}
+		for _, child := range module.ChildModules {
+			walkModule(child)
+		}
+	}
+	walkModule(values.RootModule)
+
+	attributeTypes := make([]AttributeType, 0, len(types))
+	for key, t := range types {
+		attributeTypes = append(attributeTypes, AttributeType{Mode: key.mode, ResourceType: key.resourceType, AttributePath: key.path, Type: t})
+	}
+	sort.Slice(attributeTypes, func(i, j int) bool {
+		a, b := attributeTypes[i], attributeTypes[j]
+		if a.Mode != b.Mode {
+			return a.Mode < b.Mode
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		return a.AttributePath < b.AttributePath
+	})
+	return attributeTypes
+}
+
+// collectAttributeTypes calls add with the path and type of every attribute of an object, descending into
+// nested objects and list elements
+func collectAttributeTypes(object map[string]interface{}, prefix string, add func(path, t string)) {
+	for key, value := range object {
+		collectValueType(value, prefix+key, add)
+	}
+}
+
+func collectValueType(value interface{}, path string, add func(path, t string)) {
+	t := valueType(value)
+	if t == "" {
+		return
+	}
+	add(path, t)
+	collectNestedTypes(value, path, add)
+}
+
+// collectNestedTypes descends into the attributes of an object, and of the objects of a list, whose element
+// types are part of the type of the list
+func collectNestedTypes(value interface{}, path string, add func(path, t string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		collectAttributeTypes(v, path+".", add)
+	case []interface{}:
+		for _, element := range v {
+			collectNestedTypes(element, path+"[*]", add)
+		}
+	}
+}
+
+// valueType returns the Terraform type of a decoded JSON value, empty for null
+func valueType(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "bool"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		var elementTypes []string
+		for _, element := range v {
+			if t := valueType(element); t != "" && !contains(elementTypes, t) {
+				elementTypes = append(elementTypes, t)
+			}
+		}
+		switch len(elementTypes) {
+		case 0:
+			return "list"
+		case 1:
+			return "list(" + elementTypes[0] + ")"
+		}
+		return "tuple"
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const attributeTypesShowJSON = `{
+  "format_version": "1.0",
+  "values": {"root_module": {
+    "resources": [
+      {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+       "values": {"id": "i-1", "cpu_core_count": 2, "monitoring": false, "tags": {"Name": "web"}, "ipv6_addresses": [],
+                  "ebs_block_device": [{"volume_size": 8, "kms_key_id": null}]}}
+    ],
+    "child_modules": [{"address": "module.apps", "resources": [
+      {"address": "module.apps.aws_instance.app", "mode": "managed", "type": "aws_instance", "name": "app",
+       "values": {"id": "i-2", "ipv6_addresses": ["2001:db8::1"], "ebs_block_device": [{"kms_key_id": "key"}]}},
+      {"address": "module.apps.data.aws_ami.base", "mode": "data", "type": "aws_ami", "name": "base",
+       "values": {"id": "ami-1", "block_device_mappings": [1, "a"]}}
+    ]}]
+  }}
+}`
+
+func TestAttributeTypes(t *testing.T) {
+	data, err := parseAndValidate(context.Background(), strings.NewReader(attributeTypesShowJSON), BackendOptions{AttributeTypes: true})
+	require.NoError(t, err)
+	require.NotNil(t, data.ShowJSON)
+
+	assert.Equal(t, []AttributeType{
+		{Mode: "data", ResourceType: "aws_ami", AttributePath: "block_device_mappings", Type: "tuple"},
+		{Mode: "data", ResourceType: "aws_ami", AttributePath: "id", Type: "string"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "cpu_core_count", Type: "number"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "ebs_block_device", Type: "list(object)"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "ebs_block_device[*].kms_key_id", Type: "string"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "ebs_block_device[*].volume_size", Type: "number"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "id", Type: "string"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "ipv6_addresses", Type: "list(string)"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "monitoring", Type: "bool"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "tags", Type: "object"},
+		{Mode: "managed", ResourceType: "aws_instance", AttributePath: "tags.Name", Type: "string"},
+	}, AttributeTypes(data.ShowJSON))
+
+	// the values are only decoded when asked for
+	data, err = parseAndValidate(context.Background(), strings.NewReader(attributeTypesShowJSON), BackendOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, AttributeTypes(data.ShowJSON))
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+const (
+	awsPartitionID      = "aws"
+	awsCnPartitionID    = "aws-cn"
+	awsUsGovPartitionID = "aws-us-gov"
+	awsIsoPartitionID   = "aws-iso"
+	awsIsoBPartitionID  = "aws-iso-b"
+)
+
+// partitionDefaultRegions are used to look up the bucket region, as the lookup has to be sent to the bucket's partition
+var partitionDefaultRegions = map[string]string{
+	awsPartitionID:      "us-east-1",
+	awsCnPartitionID:    "cn-north-1",
+	awsUsGovPartitionID: "us-gov-west-1",
+	awsIsoPartitionID:   "us-iso-east-1",
+	awsIsoBPartitionID:  "us-isob-east-1",
+}
+
+// partitionRegions are the region patterns of the partitions, as used by the endpoint resolvers of the sdk
+var partitionRegions = []struct {
+	id      string
+	pattern *regexp.Regexp
+}{
+	{awsPartitionID, regexp.MustCompile(`^(us|eu|ap|sa|ca|me|af)\-\w+\-\d+$`)},
+	{awsCnPartitionID, regexp.MustCompile(`^cn\-\w+\-\d+$`)},
+	{awsUsGovPartitionID, regexp.MustCompile(`^us\-gov\-\w+\-\d+$`)},
+	{awsIsoPartitionID, regexp.MustCompile(`^us\-iso\-\w+\-\d+$`)},
+	{awsIsoBPartitionID, regexp.MustCompile(`^us\-isob\-\w+\-\d+$`)},
+}
+
+// partitionForRegion returns the partition of a region, false for unknown regions such as the auto region of R2
+func partitionForRegion(region string) (string, bool) {
+	for _, p := range partitionRegions {
+		if p.pattern.MatchString(region) {
+			return p.id, true
+		}
+	}
+	return "", false
+}
+
+// resolvePartition returns the partition of the s3 backend, from the explicit partition, the region or the role arn,
+// in that order, defaulting to the standard partition
+func resolvePartition(b S3BackendConfig) (string, error) {
+	partition := b.Partition
+	if partition == "" && arn.IsARN(b.Bucket) {
+		accessPoint, err := parseAccessPointARN(b.Bucket)
+		if err != nil {
+			return "", err
+		}
+		partition = accessPoint.Partition
+	}
+	if partition == "" && b.Region != "" {
+		if p, ok := partitionForRegion(b.Region); ok {
+			partition = p
+		}
+	}
+	if b.RoleArn != "" {
+		roleArn, err := arn.Parse(b.RoleArn)
+		if err != nil {
+			return "", err
+		}
+		if partition == "" {
+			partition = roleArn.Partition
+		} else if roleArn.Partition != partition {
+			return "", fmt.Errorf("role_arn partition %q does not match the %q partition of the backend", roleArn.Partition, partition)
+		}
+	}
+	if partition == "" {
+		partition = awsPartitionID
+	}
+	if _, ok := partitionDefaultRegions[partition]; !ok {
+		return "", fmt.Errorf("unsupported partition %q", partition)
+	}
+	return partition, nil
+}
+
+// parseAccessPointARN parses an s3 access point arn such as arn:aws:s3:us-east-1:123456789012:accesspoint/states
+func parseAccessPointARN(bucket string) (arn.ARN, error) {
+	accessPoint, err := arn.Parse(bucket)
+	if err != nil {
+		return arn.ARN{}, err
+	}
+	if accessPoint.Service != "s3" || !strings.HasPrefix(accessPoint.Resource, "accesspoint/") && !strings.HasPrefix(accessPoint.Resource, "accesspoint:") {
+		return arn.ARN{}, fmt.Errorf("bucket arn %q is not an s3 access point", bucket)
+	}
+	if accessPoint.Region == "" {
+		return arn.ARN{}, fmt.Errorf("access point arn %q has no region", bucket)
+	}
+	return accessPoint, nil
+}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePartition(t *testing.T) {
+	for _, tc := range []struct {
+		config    S3BackendConfig
+		partition string
+		err       string
+	}{
+		{config: S3BackendConfig{}, partition: "aws"},
+		{config: S3BackendConfig{Region: "eu-west-1"}, partition: "aws"},
+		{config: S3BackendConfig{Region: "us-gov-west-1"}, partition: "aws-us-gov"},
+		{config: S3BackendConfig{Region: "cn-northwest-1"}, partition: "aws-cn"},
+		{config: S3BackendConfig{Region: "auto"}, partition: "aws"},
+		{config: S3BackendConfig{Partition: "aws-cn"}, partition: "aws-cn"},
+		{config: S3BackendConfig{RoleArn: "arn:aws-us-gov:iam::123456789012:role/state"}, partition: "aws-us-gov"},
+		{config: S3BackendConfig{Region: "cn-north-1", RoleArn: "arn:aws-cn:iam::123456789012:role/state"}, partition: "aws-cn"},
+		{
+			config: S3BackendConfig{Region: "cn-north-1", RoleArn: "arn:aws:iam::123456789012:role/state"},
+			err:    `role_arn partition "aws" does not match the "aws-cn" partition of the backend`,
+		},
+		{config: S3BackendConfig{Bucket: "arn:aws-us-gov:s3:us-gov-east-1:123456789012:accesspoint/states"}, partition: "aws-us-gov"},
+		{config: S3BackendConfig{Partition: "aws-moon"}, err: `unsupported partition "aws-moon"`},
+		{config: S3BackendConfig{RoleArn: "state"}, err: "arn: invalid prefix"},
+	} {
+		partition, err := resolvePartition(tc.config)
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, tc.partition, partition)
+	}
+}
+
+func TestParseAccessPointARN(t *testing.T) {
+	accessPoint, err := parseAccessPointARN("arn:aws:s3:eu-central-1:123456789012:accesspoint/states")
+	assert.NoError(t, err)
+	assert.Equal(t, "eu-central-1", accessPoint.Region)
+	assert.Equal(t, "123456789012", accessPoint.AccountID)
+
+	_, err = parseAccessPointARN("arn:aws:iam::123456789012:role/state")
+	assert.EqualError(t, err, `bucket arn "arn:aws:iam::123456789012:role/state" is not an s3 access point`)
+
+	_, err = parseAccessPointARN("arn:aws:s3::123456789012:accesspoint/states")
+	assert.EqualError(t, err, `access point arn "arn:aws:s3::123456789012:accesspoint/states" has no region`)
+}
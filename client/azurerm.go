@@ -0,0 +1,284 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAzureTimeout = time.Minute
+	// azureStorageVersion is the storage REST API version of the requests, the first to serve ADLS Gen2
+	// filesystems with shared key and SAS authorization alike
+	azureStorageVersion = "2020-10-02"
+)
+
+// AzureRMBackendConfig reads the state from an Azure storage account, like the azurerm backend of terraform.
+// The state is either a blob of a container, addressed by ContainerName and Key, or a file of an ADLS Gen2
+// filesystem (a storage account with hierarchical namespace), addressed by Filesystem and Path.
+type AzureRMBackendConfig struct {
+	StorageAccountName string `yaml:"storage_account_name"`
+	ContainerName      string `yaml:"container_name,omitempty"`
+	Key                string `yaml:"key,omitempty"`
+	Filesystem         string `yaml:"filesystem,omitempty"`
+	// Path is the path of the state file from the root of the filesystem, such as teams/data/terraform.tfstate
+	Path string `yaml:"path,omitempty"`
+	// AccessKey authorizes the requests with the shared key of the account, SASToken with a shared access
+	// signature, they fall back to ARM_ACCESS_KEY and ARM_SAS_TOKEN like terraform
+	AccessKey string `yaml:"access_key,omitempty"`
+	SASToken  string `yaml:"sas_token,omitempty"`
+	// Endpoint replaces the https://<account>.blob.core.windows.net or https://<account>.dfs.core.windows.net
+	// endpoint of the account, for sovereign clouds or emulators such as Azurite
+	Endpoint       string        `yaml:"endpoint,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	TLSConfig      `yaml:",inline"`
+	BackendOptions `yaml:",inline"`
+}
+
+func (b AzureRMBackendConfig) datalake() bool {
+	return b.Filesystem != ""
+}
+
+func (b AzureRMBackendConfig) credentials() (accessKey string, sasToken string) {
+	return envFallback(b.AccessKey, "ARM_ACCESS_KEY"), strings.TrimPrefix(envFallback(b.SASToken, "ARM_SAS_TOKEN"), "?")
+}
+
+func (b AzureRMBackendConfig) Validate() error {
+	if b.StorageAccountName == "" {
+		return errors.New("azurerm backend requires storage_account_name")
+	}
+	blob := b.ContainerName != "" || b.Key != ""
+	switch {
+	case blob && b.datalake():
+		return errors.New("only one of container_name and filesystem can be set")
+	case b.datalake():
+		if _, err := datalakePath(b.Path); err != nil {
+			return err
+		}
+	case b.ContainerName == "" || b.Key == "":
+		return errors.New("azurerm backend requires container_name and key, or filesystem and path")
+	case b.Path != "":
+		return errors.New("path is only used with filesystem, blobs are addressed by key")
+	}
+	accessKey, sasToken := b.credentials()
+	if accessKey != "" && sasToken != "" {
+		return errors.New("only one of access_key and sas_token can be set")
+	}
+	if accessKey != "" {
+		if _, err := base64.StdEncoding.DecodeString(accessKey); err != nil {
+			return fmt.Errorf("invalid access_key: %w", err)
+		}
+	}
+	if b.Endpoint != "" {
+		if u, err := url.Parse(b.Endpoint); err != nil || u.Host == "" {
+			return fmt.Errorf("invalid endpoint %q", b.Endpoint)
+		}
+	}
+	if b.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if _, err := b.tlsConfig(); err != nil {
+		return err
+	}
+	return b.BackendOptions.Validate()
+}
+
+// datalakePath normalizes the path of a file of an ADLS Gen2 filesystem. Unlike blob keys, which are opaque
+// names, the path is split into directories: leading and repeated slashes are dropped and . or .. segments
+// are rejected, as the service would resolve them to another file.
+func datalakePath(path string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "":
+			continue
+		case ".", "..":
+			return "", fmt.Errorf("invalid path %q: relative segments are not allowed", path)
+		}
+		segments = append(segments, segment)
+	}
+	if len(segments) == 0 {
+		return "", errors.New("azurerm backend requires path with filesystem")
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// stateURL returns the url of the state, on the dfs endpoint for ADLS Gen2 filesystems and on the blob
+// endpoint otherwise
+func (b AzureRMBackendConfig) stateURL() (*url.URL, error) {
+	service, container, name := "blob", b.ContainerName, b.Key
+	if b.datalake() {
+		path, err := datalakePath(b.Path)
+		if err != nil {
+			return nil, err
+		}
+		service, container, name = "dfs", b.Filesystem, path
+	}
+	endpoint := b.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.core.windows.net", b.StorageAccountName, service)
+	}
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/") + "/" + url.PathEscape(container) + "/" + strings.Join(segments, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint %q: %w", b.Endpoint, err)
+	}
+	return u, nil
+}
+
+// NewAzureRMTerraformBackend reads the state from an Azure storage container or ADLS Gen2 filesystem
+func NewAzureRMTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b AzureRMBackendConfig
+	if err := decodeBackendConfig(config, AZURERM, &b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultAzureTimeout
+	}
+
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	// the timeout covers reading the body, which is streamed into the parser
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	req, err := b.newRequest(ctx, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tfstate %s: %w", b.location(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get tfstate %s: %w", b.location(), httpStatusError{resp.StatusCode, resp.Status})
+	}
+	// reading a directory of a filesystem succeeds with an empty body
+	if resp.Header.Get("x-ms-resource-type") == "directory" {
+		return nil, fmt.Errorf("%s is a directory, not a terraform state", b.location())
+	}
+
+	terraformData, err := parseAndValidate(ctx, resp.Body, b.BackendOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TerraformBackend{
+		BackendType: AZURERM,
+		BackendName: config.BackendName,
+		Data:        terraformData,
+	}, nil
+}
+
+// newRequest creates an authorized request of the state
+func (b AzureRMBackendConfig) newRequest(ctx context.Context, method string) (*http.Request, error) {
+	stateURL, err := b.stateURL()
+	if err != nil {
+		return nil, err
+	}
+	accessKey, sasToken := b.credentials()
+	if sasToken != "" {
+		stateURL.RawQuery = sasToken
+	}
+	req, err := http.NewRequestWithContext(ctx, method, stateURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	if accessKey != "" {
+		if err := signSharedKey(req, b.StorageAccountName, accessKey); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// location describes the state for error messages
+func (b AzureRMBackendConfig) location() string {
+	if b.datalake() {
+		return fmt.Sprintf("%s@%s/%s", b.Filesystem, b.StorageAccountName, strings.TrimPrefix(b.Path, "/"))
+	}
+	return fmt.Sprintf("%s/%s/%s", b.StorageAccountName, b.ContainerName, b.Key)
+}
+
+// signSharedKey authorizes the request with the shared key of the storage account
+func signSharedKey(req *http.Request, account string, accessKey string) error {
+	key, err := base64.StdEncoding.DecodeString(accessKey)
+	if err != nil {
+		return fmt.Errorf("invalid access_key: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(sharedKeyStringToSign(req, account)))
+	req.Header.Set("Authorization", "SharedKey "+account+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return nil
+}
+
+// sharedKeyStringToSign builds the string to sign of the shared key authorization of the blob and dfs services
+func sharedKeyStringToSign(req *http.Request, account string) string {
+	contentLength := req.Header.Get("Content-Length")
+	if contentLength == "0" {
+		contentLength = ""
+	}
+	lines := []string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		// the date is sent as x-ms-date
+		"",
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}
+
+	var headers []string
+	for name := range req.Header {
+		if name := strings.ToLower(name); strings.HasPrefix(name, "x-ms-") {
+			headers = append(headers, name)
+		}
+	}
+	sort.Strings(headers)
+	for _, name := range headers {
+		lines = append(lines, name+":"+strings.TrimSpace(req.Header.Get(name)))
+	}
+
+	resource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+	return strings.Join(append(lines, resource), "\n")
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAzureAccessKey = "c2VjcmV0LWtleQ=="
+
+func TestAzureRMBackend(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sig") == "" {
+			key, _ := base64.StdEncoding.DecodeString(testAzureAccessKey)
+			mac := hmac.New(sha256.New, key)
+			mac.Write([]byte(sharedKeyStringToSign(r, "tfstates")))
+			if r.Header.Get("Authorization") != "SharedKey tfstates:"+base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+		switch r.URL.Path {
+		case "/states/prod.tfstate", "/data/teams/analytics/terraform.tfstate":
+			_, _ = w.Write(state)
+		case "/data/teams":
+			w.Header().Set("x-ms-resource-type", "directory")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	config := func(attrs map[string]interface{}) *BackendConfigBlock {
+		attrs["storage_account_name"] = "tfstates"
+		attrs["endpoint"] = srv.URL
+		return &BackendConfigBlock{BackendName: "azure", BackendType: string(AZURERM), ConfigAttrs: attrs}
+	}
+
+	b, err := NewBackend(context.Background(), config(map[string]interface{}{
+		"container_name": "states", "key": "prod.tfstate", "access_key": testAzureAccessKey,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, AZURERM, b.BackendType)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	// filesystem paths are normalized
+	b, err = NewBackend(context.Background(), config(map[string]interface{}{
+		"filesystem": "data", "path": "/teams//analytics/terraform.tfstate", "sas_token": "?sv=2020-10-02&sig=abc",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	_, err = NewBackend(context.Background(), config(map[string]interface{}{
+		"filesystem": "data", "path": "teams", "access_key": testAzureAccessKey,
+	}))
+	assert.ErrorContains(t, err, "data@tfstates/teams is a directory")
+
+	_, err = NewBackend(context.Background(), config(map[string]interface{}{
+		"filesystem": "data", "path": "teams/missing.tfstate", "access_key": testAzureAccessKey,
+	}))
+	assert.ErrorContains(t, err, "404 Not Found")
+	assert.True(t, IsUnavailableError(err))
+
+	_, err = NewBackend(context.Background(), config(map[string]interface{}{
+		"container_name": "states", "key": "prod.tfstate", "access_key": "b3RoZXIta2V5",
+	}))
+	assert.ErrorContains(t, err, "403 Forbidden")
+}
+
+func TestAzureRMBackendConfigValidate(t *testing.T) {
+	t.Setenv("ARM_ACCESS_KEY", "")
+	t.Setenv("ARM_SAS_TOKEN", "")
+	for _, tc := range []struct {
+		config AzureRMBackendConfig
+		err    string
+	}{
+		{AzureRMBackendConfig{StorageAccountName: "a", ContainerName: "c", Key: "k"}, ""},
+		{AzureRMBackendConfig{StorageAccountName: "a", Filesystem: "f", Path: "x/terraform.tfstate"}, ""},
+		{AzureRMBackendConfig{ContainerName: "c", Key: "k"}, "requires storage_account_name"},
+		{AzureRMBackendConfig{StorageAccountName: "a", ContainerName: "c"}, "requires container_name and key, or filesystem and path"},
+		{AzureRMBackendConfig{StorageAccountName: "a", ContainerName: "c", Key: "k", Filesystem: "f"}, "only one of container_name and filesystem"},
+		{AzureRMBackendConfig{StorageAccountName: "a", ContainerName: "c", Key: "k", Path: "p"}, "path is only used with filesystem"},
+		{AzureRMBackendConfig{StorageAccountName: "a", Filesystem: "f", Path: "/"}, "requires path with filesystem"},
+		{AzureRMBackendConfig{StorageAccountName: "a", Filesystem: "f", Path: "x/../y"}, "relative segments"},
+		{AzureRMBackendConfig{StorageAccountName: "a", ContainerName: "c", Key: "k", AccessKey: "a2V5", SASToken: "sig=x"}, "only one of access_key and sas_token"},
+		{AzureRMBackendConfig{StorageAccountName: "a", ContainerName: "c", Key: "k", AccessKey: "not base64"}, "invalid access_key"},
+	} {
+		err := tc.config.Validate()
+		if tc.err == "" {
+			assert.NoError(t, err)
+			continue
+		}
+		assert.ErrorContains(t, err, tc.err)
+	}
+}
+
+func TestSharedKeyStringToSign(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://tfstates.dfs.core.windows.net/data/teams/terraform.tfstate?timeout=30", nil)
+	require.NoError(t, err)
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	req.Header.Set("x-ms-date", "Mon, 01 Aug 2022 10:00:00 GMT")
+
+	assert.Equal(t, "GET\n\n\n\n\n\n\n\n\n\n\n\n"+
+		"x-ms-date:Mon, 01 Aug 2022 10:00:00 GMT\n"+
+		"x-ms-version:2020-10-02\n"+
+		"/tfstates/data/teams/terraform.tfstate\n"+
+		"timeout:30", sharedKeyStringToSign(req, "tfstates"))
+}
@@ -1,20 +1,26 @@
 package client
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/arn"
-	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
-	"gopkg.in/yaml.v3"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 type BackendType string
@@ -22,129 +28,676 @@ type BackendType string
 // currently supported backends type
 // full list - https://www.terraform.io/docs/language/settings/backends/index.html
 const (
-	LOCAL BackendType = "local"
-	S3    BackendType = "s3"
+	LOCAL    BackendType = "local"
+	S3       BackendType = "s3"
+	R2       BackendType = "r2"
+	SCALEWAY BackendType = "scaleway"
+	GRPC     BackendType = "grpc"
+	IPFS     BackendType = "ipfs"
+	AZURERM  BackendType = "azurerm"
+	WEBDAV   BackendType = "webdav"
+	EXEC     BackendType = "exec"
+	MONGODB  BackendType = "mongodb"
 )
 
 // BackendConfigBlock - abstract backend config
 type BackendConfigBlock struct {
-	BackendName string                 `yaml:"name"`
-	BackendType string                 `yaml:"backend"`
-	ConfigAttrs map[string]interface{} `yaml:",inline"`
+	BackendName string `yaml:"name"`
+	BackendType string `yaml:"backend"`
+	// Labels are matched by the label_selector of the provider config
+	Labels map[string]string `yaml:"labels,omitempty"`
+	// Fallbacks are tried in order when the state is missing or unreachable at the primary location, each one
+	// overrides the attributes of the primary location, or sets its own backend
+	Fallbacks   []map[string]interface{} `yaml:"fallbacks,omitempty"`
+	ConfigAttrs map[string]interface{}   `yaml:",inline"`
 }
 
 type TerraformBackend struct {
 	BackendType BackendType
 	BackendName string
+	Labels      map[string]string
 	Data        *TerraformData
+	// FetchedAt is the time the state was fetched, the backends of a fetch are read one after the other
+	FetchedAt time.Time
+	// Fallback is the 1-based index of the fallback the state was read from, 0 for the primary location
+	Fallback int
+	// Workspaces discovered next to the state, when the backend supports listing them
+	Workspaces []Workspace
+	// Status of the state, the only field set besides the name, type and labels when the provider checks the
+	// status of the backends without fetching them
+	Status *BackendStatus
 }
 
+// BackendOptions are the state parsing options shared by all backend types
+type BackendOptions struct {
+	// OutputsOnly skips decoding the resources of the state, only outputs are emitted
+	OutputsOnly bool `yaml:"outputs_only,omitempty"`
+	// MinTerraformVersion warns about states written by an older terraform, or whose version can't be compared,
+	// or rejects them when EnforceMinTerraformVersion is set
+	MinTerraformVersion        string `yaml:"min_terraform_version,omitempty"`
+	EnforceMinTerraformVersion bool   `yaml:"enforce_min_terraform_version,omitempty"`
+	// CanonicalJSON re-encodes the attributes of the instances with sorted keys and without insignificant
+	// whitespace, so equal attributes are stored as equal bytes
+	CanonicalJSON bool `yaml:"canonical_json,omitempty"`
+	// CurrentOnly drops the deposed instances of the instance keys which have a current instance, keeping the
+	// deposed instances of keys without one
+	CurrentOnly bool `yaml:"current_only,omitempty"`
+	// AttributeTypes decodes the resource values of `terraform show -json`, to infer the types of their attributes
+	AttributeTypes bool `yaml:"attribute_types,omitempty"`
+}
+
+func (o BackendOptions) Validate() error {
+	if o.MinTerraformVersion != "" {
+		if _, err := parseTerraformVersion(o.MinTerraformVersion); err != nil {
+			return fmt.Errorf("invalid min_terraform_version: %w", err)
+		}
+	}
+	return nil
+}
+
+// defaultLocalReadTimeout is generous for local disks, it only guards against hung mounts
+const defaultLocalReadTimeout = 5 * time.Minute
+
 type LocalBackendConfig struct {
 	Path string `yaml:"path"`
+	// ReadTimeout bounds the read of the state file, for paths on slow storage such as FUSE mounts
+	ReadTimeout time.Duration `yaml:"read_timeout,omitempty"`
+	// ReadBufferSize streams the state into the parser through a buffer of that many bytes instead of reading
+	// it whole, for large states on network filesystems such as EFS
+	ReadBufferSize int `yaml:"read_buffer_size,omitempty"`
+	BackendOptions `yaml:",inline"`
+}
+
+func (b LocalBackendConfig) Validate() error {
+	if b.Path == "" {
+		return errors.New("local backend requires path")
+	}
+	if b.ReadTimeout < 0 {
+		return errors.New("read_timeout must not be negative")
+	}
+	if b.ReadBufferSize < 0 {
+		return errors.New("read_buffer_size must not be negative")
+	}
+	return b.BackendOptions.Validate()
 }
 
 type S3BackendConfig struct {
-	Bucket  string `yaml:"bucket"`
-	Key     string `yaml:"key"`
-	Region  string `yaml:"region"`
-	RoleArn string `yaml:"role_arn,omitempty"`
+	// Bucket is the bucket name or an access point arn
+	Bucket string `yaml:"bucket"`
+	Key    string `yaml:"key"`
+	// KeyTemplate builds the key from ${account_id} of the caller identity and ${bucket}, ${region},
+	// ${partition} or ${role_arn} of the config, for example states/${account_id}/${region}/terraform.tfstate
+	KeyTemplate    string `yaml:"key_template,omitempty"`
+	Region         string `yaml:"region"`
+	Partition      string `yaml:"partition,omitempty"`
+	RoleArn        string `yaml:"role_arn,omitempty"`
+	Endpoint       string `yaml:"endpoint,omitempty"`
+	ForcePathStyle bool   `yaml:"force_path_style,omitempty"`
+	AccessKey      string `yaml:"access_key,omitempty"`
+	SecretKey      string `yaml:"secret_key,omitempty"`
+	// CredentialCache caches the credentials of the assumed role to CredentialCachePath (default ~/.aws/cli/cache)
+	// and reuses them until they expire, like the AWS CLI
+	CredentialCache     bool   `yaml:"credential_cache,omitempty"`
+	CredentialCachePath string `yaml:"credential_cache_path,omitempty"`
+	// CredentialRefreshWindow is how long before their expiry the assumed role credentials are refreshed,
+	// defaults to 5m
+	CredentialRefreshWindow time.Duration `yaml:"credential_refresh_window,omitempty"`
+	// ListWorkspaces lists the workspace states stored under WorkspaceKeyPrefix, without fetching them
+	ListWorkspaces     bool   `yaml:"list_workspaces,omitempty"`
+	WorkspaceKeyPrefix string `yaml:"workspace_key_prefix,omitempty"`
+	// ListRateLimit caps the list requests of the workspace listing, in requests per second
+	ListRateLimit   float64 `yaml:"list_rate_limit,omitempty"`
+	SignatureConfig `yaml:",inline"`
+	TLSConfig       `yaml:",inline"`
+	BackendOptions  `yaml:",inline"`
+}
+
+// Validate checks the fields of the config, the partition is resolved without calling AWS
+func (b S3BackendConfig) Validate() error {
+	if b.Bucket == "" {
+		return errors.New("bucket is required")
+	}
+	if b.Key != "" && b.KeyTemplate != "" {
+		return errors.New("only one of key and key_template can be set")
+	}
+	if b.Key == "" && b.KeyTemplate == "" {
+		return errors.New("one of key and key_template is required")
+	}
+	if b.ListRateLimit < 0 {
+		return errors.New("list_rate_limit must not be negative")
+	}
+	if b.CredentialRefreshWindow < 0 {
+		return errors.New("credential_refresh_window must not be negative")
+	}
+	if _, err := resolvePartition(b); err != nil {
+		return err
+	}
+	if _, err := b.tlsConfig(); err != nil {
+		return err
+	}
+	return b.BackendOptions.Validate()
+}
+
+// R2BackendConfig is a preset of the s3 backend for Cloudflare R2
+type R2BackendConfig struct {
+	AccountID       string `yaml:"account_id"`
+	Bucket          string `yaml:"bucket"`
+	Key             string `yaml:"key"`
+	AccessKey       string `yaml:"access_key,omitempty"`
+	SecretKey       string `yaml:"secret_key,omitempty"`
+	SignatureConfig `yaml:",inline"`
+	TLSConfig       `yaml:",inline"`
+	BackendOptions  `yaml:",inline"`
+}
+
+// S3Config converts the preset into the equivalent s3 backend config, falling back to
+// R2_ACCESS_KEY_ID and R2_SECRET_ACCESS_KEY when no credentials were configured
+func (r R2BackendConfig) S3Config() S3BackendConfig {
+	b := S3BackendConfig{
+		Bucket:          r.Bucket,
+		Key:             r.Key,
+		Region:          "auto",
+		Endpoint:        fmt.Sprintf("https://%s.r2.cloudflarestorage.com", r.AccountID),
+		ForcePathStyle:  true,
+		AccessKey:       r.AccessKey,
+		SecretKey:       r.SecretKey,
+		SignatureConfig: r.SignatureConfig,
+		TLSConfig:       r.TLSConfig,
+		BackendOptions:  r.BackendOptions,
+	}
+	b.AccessKey = envFallback(b.AccessKey, "R2_ACCESS_KEY_ID")
+	b.SecretKey = envFallback(b.SecretKey, "R2_SECRET_ACCESS_KEY")
+	return b
+}
+
+func (r R2BackendConfig) Validate() error {
+	if r.AccountID == "" {
+		return errors.New("r2 backend requires account_id")
+	}
+	return r.S3Config().Validate()
+}
+
+// ScalewayBackendConfig is a preset of the s3 backend for Scaleway Object Storage
+type ScalewayBackendConfig struct {
+	Region          string `yaml:"region,omitempty"`
+	Bucket          string `yaml:"bucket"`
+	Key             string `yaml:"key"`
+	AccessKey       string `yaml:"access_key,omitempty"`
+	SecretKey       string `yaml:"secret_key,omitempty"`
+	SignatureConfig `yaml:",inline"`
+	TLSConfig       `yaml:",inline"`
+	BackendOptions  `yaml:",inline"`
+}
+
+// S3Config converts the preset into the equivalent s3 backend config, falling back to the
+// SCW_DEFAULT_REGION, SCW_ACCESS_KEY and SCW_SECRET_KEY environment variables of the Scaleway CLI
+func (c ScalewayBackendConfig) S3Config() S3BackendConfig {
+	region := envFallback(c.Region, "SCW_DEFAULT_REGION")
+	if region == "" {
+		region = "fr-par"
+	}
+	return S3BackendConfig{
+		Bucket:          c.Bucket,
+		Key:             c.Key,
+		Region:          region,
+		Endpoint:        fmt.Sprintf("https://s3.%s.scw.cloud", region),
+		ForcePathStyle:  true,
+		AccessKey:       envFallback(c.AccessKey, "SCW_ACCESS_KEY"),
+		SecretKey:       envFallback(c.SecretKey, "SCW_SECRET_KEY"),
+		SignatureConfig: c.SignatureConfig,
+		TLSConfig:       c.TLSConfig,
+		BackendOptions:  c.BackendOptions,
+	}
+}
+
+func (c ScalewayBackendConfig) Validate() error {
+	return c.S3Config().Validate()
+}
+
+// envFallback returns value, or the first non empty environment variable of envs if value is empty
+func envFallback(value string, envs ...string) string {
+	for _, env := range envs {
+		if value != "" {
+			break
+		}
+		value = os.Getenv(env)
+	}
+	return value
+}
+
+// AttributeTransformerFunc receives the decoded attributes of a resource instance and returns the attributes to store.
+// The attributes are decoded with UseNumber, numbers are json.Number and not float64, so large integers keep their
+// precision.
+type AttributeTransformerFunc func(resourceType string, attrs map[string]interface{}) map[string]interface{}
+
+// AttributeTransformer lets embedders redact or enrich instance attributes while the state is parsed, for example
+// hashing IP addresses or dropping fields. It must be set before the provider is configured, nil keeps the attributes
+// as they are in the state.
+var AttributeTransformer AttributeTransformerFunc
+
+var (
+	ErrInvalidState            = errors.New("invalid tf state file")
+	ErrUnsupportedStateVersion = errors.New("unsupported state version")
+	ErrTerraformVersionTooOld  = errors.New("terraform version is below the minimum")
+	ErrEncryptedState          = errors.New("state is encrypted with OpenTofu state encryption")
+)
+
+// IsParseError reports whether err was caused by a state that could be fetched but not parsed
+func IsParseError(err error) bool {
+	return errors.Is(err, ErrInvalidState) || errors.Is(err, ErrUnsupportedStateVersion) || errors.Is(err, ErrEncryptedState)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// htmlPrefixes are the lowercase starts of HTML documents which proxies and CDNs return in place of the state
+var htmlPrefixes = [][]byte{[]byte("<!doctype"), []byte("<html")}
+
+// isHTML peeks the start of the body and reports whether it is an HTML document, the returned reader must be
+// used in place of the given one
+func isHTML(reader io.Reader) (io.Reader, bool) {
+	br := bufio.NewReader(reader)
+	head, _ := br.Peek(512)
+	head = bytes.ToLower(bytes.TrimLeft(head, " \t\r\n\ufeff"))
+	for _, prefix := range htmlPrefixes {
+		if bytes.HasPrefix(head, prefix) {
+			return br, true
+		}
+	}
+	return br, false
 }
 
 // parseAndValidate received reader turn in into TerraformData state and validate the state version
-func parseAndValidate(reader io.Reader) (*TerraformData, error) {
-	var s TerraformData
-	if err := json.NewDecoder(reader).Decode(&s.State); err != nil {
-		return nil, fmt.Errorf("invalid tf state file")
+func parseAndValidate(ctx context.Context, reader io.Reader, opts BackendOptions) (*TerraformData, error) {
+	var doc stateDocument
+	var skip []string
+	if opts.OutputsOnly {
+		skip = append(skip, "resources")
+	}
+	if !opts.AttributeTypes {
+		skip = append(skip, "values", "planned_values")
+	}
+	counter := &countingReader{r: reader}
+	if err := decodeDocument(ctx, json.NewDecoder(counter), &doc, skip); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, ErrInvalidState
+	}
+	if doc.EncryptedData != nil {
+		return nil, ErrEncryptedState
+	}
+	// the decoder stops at the end of the document, the trailing bytes are counted too
+	if _, err := io.Copy(io.Discard, counter); err != nil {
+		return nil, err
+	}
+	s := TerraformData{State: doc.State, RawBytes: counter.n}
+	if opts.MinTerraformVersion != "" {
+		if err := checkMinTerraformVersion(s.State.TerraformVersion, opts.MinTerraformVersion); err != nil {
+			// without enforcement every failed check is a warning, an unparsable terraform_version included
+			if opts.EnforceMinTerraformVersion {
+				return nil, err
+			}
+			s.Warnings = append(s.Warnings, err.Error())
+		}
+	}
+	if doc.FormatVersion != "" {
+		// output of `terraform show -json` has no state version of its own
+		s.ShowJSON = &doc.ShowJSON
+		return &s, nil
 	}
 	if s.State.Version != StateVersion {
-		return nil, fmt.Errorf("unsupported state version %d", s.State.Version)
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedStateVersion, s.State.Version)
+	}
+	if opts.CurrentOnly {
+		dropReplacedDeposed(&s.State)
+	}
+	if AttributeTransformer != nil {
+		// the transformed attributes are re-encoded, which makes them canonical too
+		if err := transformAttributes(&s.State, AttributeTransformer); err != nil {
+			return nil, err
+		}
+	} else if opts.CanonicalJSON {
+		if err := transformAttributes(&s.State, keepAttributes); err != nil {
+			return nil, err
+		}
 	}
 	return &s, nil
 }
 
-func NewS3TerraformBackend(config *BackendConfigBlock) (*TerraformBackend, error) {
-	var b S3BackendConfig
-
-	cfgBytes, _ := yaml.Marshal(config.ConfigAttrs)
-	if err := yaml.Unmarshal(cfgBytes, &b); err != nil {
-		return nil, fmt.Errorf("cannot parse s3 backend config: %w", err)
+// dropReplacedDeposed removes the deposed instances of the instance keys which have a current instance, so the
+// deposed object of a count or for_each index without a current one, such as a failed create_before_destroy, is kept
+func dropReplacedDeposed(state *State) {
+	for i := range state.Resources {
+		resource := &state.Resources[i]
+		current := make(map[string]bool, len(resource.Instances))
+		for _, instance := range resource.Instances {
+			if instance.Deposed == "" {
+				current[indexKeySuffix(instance.IndexKey)] = true
+			}
+		}
+		instances := make([]Instance, 0, len(resource.Instances))
+		for _, instance := range resource.Instances {
+			if instance.Deposed == "" || !current[indexKeySuffix(instance.IndexKey)] {
+				instances = append(instances, instance)
+			}
+		}
+		resource.Instances = instances
 	}
+}
 
-	if b.Region == "" {
-		if region, err := s3manager.GetBucketRegion(
-			context.Background(),
-			session.Must(session.NewSession()),
-			b.Bucket,
-			"us-east-1",
-		); err != nil {
-			return nil, err
-		} else { //nolint:revive
-			b.Region = region
+// keepAttributes is the AttributeTransformerFunc which keeps the attributes as they are
+func keepAttributes(_ string, attrs map[string]interface{}) map[string]interface{} {
+	return attrs
+}
+
+// transformAttributes replaces the attributes of every instance with the result of transform
+func transformAttributes(state *State, transform AttributeTransformerFunc) error {
+	for i := range state.Resources {
+		resource := &state.Resources[i]
+		for j := range resource.Instances {
+			instance := &resource.Instances[j]
+			if len(instance.AttributesRaw) == 0 {
+				continue
+			}
+			dec := json.NewDecoder(bytes.NewReader(instance.AttributesRaw))
+			dec.UseNumber()
+			var attrs map[string]interface{}
+			if err := dec.Decode(&attrs); err != nil {
+				return fmt.Errorf("invalid attributes of %s.%s: %w", resource.Type, resource.Name, err)
+			}
+			raw, err := json.Marshal(transform(resource.Type, attrs))
+			if err != nil {
+				return fmt.Errorf("cannot encode transformed attributes of %s.%s: %w", resource.Type, resource.Name, err)
+			}
+			instance.AttributesRaw = raw
 		}
 	}
+	return nil
+}
 
-	sess, err := session.NewSessionWithOptions(session.Options{
-		Config: aws.Config{
-			Region: aws.String(b.Region),
-		},
-		SharedConfigState: session.SharedConfigEnable,
+// expandKeyTemplate replaces the ${name} references of the template with vars, every reference must be set
+func expandKeyTemplate(template string, vars map[string]string) (string, error) {
+	var missing []string
+	key := os.Expand(template, func(name string) string {
+		value := vars[name]
+		if value == "" {
+			missing = append(missing, name)
+		}
+		return value
 	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("key_template references unknown or empty values: %s", strings.Join(missing, ", "))
+	}
+	return key, nil
+}
 
-	if err != nil {
+func NewS3TerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b S3BackendConfig
+	if err := decodeBackendConfig(config, S3, &b); err != nil {
 		return nil, err
 	}
 
-	awsCfg := &aws.Config{}
-	if b.RoleArn != "" {
-		// if has RoleArn use it instead
-		parsedArn, err := arn.Parse(b.RoleArn)
-		if err != nil {
-			return nil, err
-		}
-		creds := stscreds.NewCredentials(sess, parsedArn.String())
-		awsCfg.Credentials = creds
+	return newS3TerraformBackend(ctx, S3, config.BackendName, b)
+}
+
+// NewR2TerraformBackend reads the state from a Cloudflare R2 bucket through its S3 compatible API
+func NewR2TerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var r R2BackendConfig
+	if err := decodeBackendConfig(config, R2, &r); err != nil {
+		return nil, err
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	return newS3TerraformBackend(ctx, R2, config.BackendName, r.S3Config())
+}
+
+// NewScalewayTerraformBackend reads the state from a Scaleway Object Storage bucket through its S3 compatible API
+func NewScalewayTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var c ScalewayBackendConfig
+	if err := decodeBackendConfig(config, SCALEWAY, &c); err != nil {
+		return nil, err
+	}
+
+	return newS3TerraformBackend(ctx, SCALEWAY, config.BackendName, c.S3Config())
+}
+
+func newS3TerraformBackend(ctx context.Context, backendType BackendType, backendName string, b S3BackendConfig) (*TerraformBackend, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	svc, err := newS3Client(ctx, &b)
+	if err != nil {
+		return nil, err
 	}
-	svc := s3.New(sess, awsCfg)
 
 	// get the tf state file
-	result, err := svc.GetObject(&s3.GetObjectInput{
+	result, err := svc.GetObject(ctx, &s3.GetObjectInput{
 		Bucket: aws.String(b.Bucket),
 		Key:    aws.String(b.Key),
 	})
 	if err != nil {
-		return nil, err
+		return nil, withKMSHint(ctx, svc, b, err)
+	}
+	defer result.Body.Close()
+
+	var state io.Reader = result.Body
+	if b.SignatureConfig.isSet() {
+		if state, err = verifyS3Signature(ctx, svc, b, result.Body); err != nil {
+			return nil, err
+		}
+	}
+
+	// the content type is not trusted, but an HTML page in place of the state is a sure sign of a misbehaving proxy
+	body, html := isHTML(state)
+	if html {
+		return nil, fmt.Errorf("s3 object %s/%s is an HTML page (content type %q), not a terraform state", b.Bucket, b.Key, aws.ToString(result.ContentType))
 	}
 
-	terraformData, err := parseAndValidate(result.Body)
+	terraformData, err := parseAndValidate(ctx, body, b.BackendOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	return &TerraformBackend{
-		BackendType: S3,
-		BackendName: config.BackendName,
+	backend := &TerraformBackend{
+		BackendType: backendType,
+		BackendName: backendName,
 		Data:        terraformData,
-	}, nil
+	}
+	if b.ListWorkspaces {
+		workspaces, err := listWorkspaces(ctx, svc, b.Bucket, b.WorkspaceKeyPrefix, b.Key, listRateLimiter(b.ListRateLimit))
+		if err != nil {
+			return nil, fmt.Errorf("cannot list workspaces: %w", err)
+		}
+		backend.Workspaces = append([]Workspace{{
+			Name:         "default",
+			Key:          b.Key,
+			Size:         result.ContentLength,
+			LastModified: aws.ToTime(result.LastModified),
+		}}, workspaces...)
+	}
+	return backend, nil
 }
 
-func NewLocalTerraformBackend(config *BackendConfigBlock) (*TerraformBackend, error) {
-	var b LocalBackendConfig
+// newS3Client creates the client of the bucket of the config, resolving the region of the bucket and the key of
+// the key template into b
+func newS3Client(ctx context.Context, b *S3BackendConfig) (*s3.Client, error) {
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
 
-	cfgBytes, _ := yaml.Marshal(config.ConfigAttrs)
-	if err := yaml.Unmarshal(cfgBytes, &b); err != nil {
-		return nil, fmt.Errorf("cannot parse local backend config: %w", err)
+	partition, err := resolvePartition(*b)
+	if err != nil {
+		return nil, err
 	}
 
-	f, err := os.Open(b.Path)
+	// access points carry their region, the bucket region can't be looked up for them
+	useARNRegion := arn.IsARN(b.Bucket)
+	if useARNRegion && b.Region == "" {
+		accessPoint, err := parseAccessPointARN(b.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		b.Region = accessPoint.Region
+	}
+
+	var loadOptions []func(*awsconfig.LoadOptions) error
+	if httpClient != nil {
+		loadOptions = append(loadOptions, awsconfig.WithHTTPClient(httpClient))
+	}
+	if b.AccessKey != "" {
+		loadOptions = append(loadOptions, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(b.AccessKey, b.SecretKey, "")))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read tfstate from %s", b.Path)
+		return nil, err
+	}
+
+	if b.Region == "" {
+		if region, err := manager.GetBucketRegion(ctx, s3.NewFromConfig(cfg), b.Bucket, func(o *s3.Options) {
+			o.Region = partitionDefaultRegions[partition]
+		}); err != nil {
+			return nil, err
+		} else { //nolint:revive
+			b.Region = region
+		}
+	}
+	// STS is called on the regional endpoint of the region, the global endpoint only serves the standard partition
+	cfg.Region = b.Region
+
+	if b.RoleArn != "" {
+		// if has RoleArn use it instead, resolvePartition already validated it. The credentials are shared with
+		// the other backends assuming the role with the same source credentials.
+		window := b.CredentialRefreshWindow
+		if window == 0 {
+			window = credentialCacheWindow
+		}
+		key := strings.Join([]string{b.RoleArn, b.AccessKey, fmt.Sprint(b.CredentialCache), b.CredentialCachePath, window.String()}, "|")
+		stsClient := sts.NewFromConfig(cfg)
+		cfg.Credentials, err = sharedCredentialsCache(key, window, func() (aws.CredentialsProvider, error) {
+			provider := stscreds.NewAssumeRoleProvider(stsClient, b.RoleArn)
+			if b.CredentialCache {
+				return newFileCacheProvider(provider, b.RoleArn, b.CredentialCachePath, window)
+			}
+			return provider, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	svc := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if b.Endpoint != "" {
+			o.EndpointResolver = s3.EndpointResolverFromURL(b.Endpoint)
+		}
+		o.UsePathStyle = b.ForcePathStyle
+		o.UseARNRegion = useARNRegion
+	})
+
+	if b.KeyTemplate != "" {
+		vars := map[string]string{
+			"bucket":    b.Bucket,
+			"region":    b.Region,
+			"partition": partition,
+			"role_arn":  b.RoleArn,
+		}
+		if strings.Contains(b.KeyTemplate, "account_id") {
+			identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+			if err != nil {
+				return nil, fmt.Errorf("cannot resolve account_id of key_template: %w", err)
+			}
+			vars["account_id"] = aws.ToString(identity.Account)
+		}
+		if b.Key, err = expandKeyTemplate(b.KeyTemplate, vars); err != nil {
+			return nil, err
+		}
+	}
+	return svc, nil
+}
+
+// readWithContext runs read in a goroutine and gives up on it when ctx is done. A read blocked in the kernel
+// can't be interrupted, so the goroutine is left behind until the read returns.
+func readWithContext(ctx context.Context, read func() ([]byte, error)) ([]byte, error) {
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		data, err := read()
+		done <- result{data, err}
+	}()
+	select {
+	case r := <-done:
+		return r.data, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func NewLocalTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b LocalBackendConfig
+	if err := decodeBackendConfig(config, LOCAL, &b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
 	}
-	defer f.Close()
 
-	terraformData, err := parseAndValidate(f)
+	path, err := expandHome(b.Path)
 	if err != nil {
 		return nil, err
 	}
+	b.Path = path
+	if b.ReadTimeout == 0 {
+		b.ReadTimeout = defaultLocalReadTimeout
+	}
+
+	readCtx, cancel := context.WithTimeout(ctx, b.ReadTimeout)
+	defer cancel()
+	timedOut := fmt.Errorf("timed out reading tfstate from %s after %s: %w", b.Path, b.ReadTimeout, context.DeadlineExceeded)
+
+	var terraformData *TerraformData
+	if b.ReadBufferSize > 0 {
+		f, err := openWithContext(readCtx, b.Path)
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, timedOut
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tfstate from %s: %w", b.Path, err)
+		}
+		defer f.Close()
+		// the state is parsed while it is read, so the read timeout bounds the parsing too
+		terraformData, err = parseAndValidate(ctx, bufio.NewReaderSize(contextReader{readCtx, f}, b.ReadBufferSize), b.BackendOptions)
+		if err != nil {
+			if errors.Is(readCtx.Err(), context.DeadlineExceeded) {
+				return nil, timedOut
+			}
+			return nil, err
+		}
+	} else {
+		state, err := readWithContext(readCtx, func() ([]byte, error) { return os.ReadFile(b.Path) })
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, timedOut
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tfstate from %s: %w", b.Path, err)
+		}
+		if terraformData, err = parseAndValidate(ctx, bytes.NewReader(state), b.BackendOptions); err != nil {
+			return nil, err
+		}
+	}
 
 	return &TerraformBackend{
 		BackendType: LOCAL,
@@ -153,22 +706,57 @@ func NewLocalTerraformBackend(config *BackendConfigBlock) (*TerraformBackend, er
 	}, nil
 }
 
+// BackendFactory creates a backend from its config block
+type BackendFactory func(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error)
+
+// backendFactories is the registry of supported backend types
+var backendFactories = map[BackendType]BackendFactory{
+	LOCAL:    NewLocalTerraformBackend,
+	S3:       NewS3TerraformBackend,
+	R2:       NewR2TerraformBackend,
+	SCALEWAY: NewScalewayTerraformBackend,
+	GRPC:     NewGRPCTerraformBackend,
+	IPFS:     NewIPFSTerraformBackend,
+	AZURERM:  NewAzureRMTerraformBackend,
+	WEBDAV:   NewWebDAVTerraformBackend,
+	EXEC:     NewExecTerraformBackend,
+	MONGODB:  NewMongoTerraformBackend,
+}
+
+// RegisterBackend adds or replaces the factory of a backend type, it must be called before the provider is configured
+func RegisterBackend(backendType BackendType, factory BackendFactory) {
+	backendFactories[backendType] = factory
+	// the built-in config type and status check no longer describe the replaced backend
+	delete(backendConfigs, backendType)
+	delete(backendStatusFuncs, backendType)
+}
+
+// SupportedBackends returns the sorted names of the registered backend types
+func SupportedBackends() []string {
+	names := make([]string, 0, len(backendFactories))
+	for backendType := range backendFactories {
+		names = append(names, string(backendType))
+	}
+	sort.Strings(names)
+	return names
+}
+
 // NewBackend initialize function
-func NewBackend(cfg *BackendConfigBlock) (*TerraformBackend, error) {
-	switch cfg.BackendType {
-	case "local":
-		localBackend, err := NewLocalTerraformBackend(cfg)
-		if err != nil {
-			return nil, err
-		}
-		return localBackend, nil
-	case "s3":
-		s3Backend, err := NewS3TerraformBackend(cfg)
-		if err != nil {
-			return nil, err
-		}
-		return s3Backend, nil
-	default:
-		return nil, errors.New("unsupported backend")
+func NewBackend(ctx context.Context, cfg *BackendConfigBlock) (*TerraformBackend, error) {
+	backend, err := newBackendWithFallbacks(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	backend.Labels = cfg.Labels
+	backend.FetchedAt = time.Now().UTC()
+	backend.Status = fetchedStatus(backend)
+	return backend, nil
+}
+
+func newBackend(ctx context.Context, cfg *BackendConfigBlock) (*TerraformBackend, error) {
+	factory, ok := backendFactories[BackendType(cfg.BackendType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported backend %q", cfg.BackendType)
 	}
+	return factory(ctx, cfg)
 }
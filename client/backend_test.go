@@ -0,0 +1,393 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestR2BackendConfig(t *testing.T) {
+	t.Setenv("R2_ACCESS_KEY_ID", "env-key")
+	t.Setenv("R2_SECRET_ACCESS_KEY", "env-secret")
+
+	b := R2BackendConfig{AccountID: "abc123", Bucket: "states", Key: "prod.tfstate"}.S3Config()
+	assert.Equal(t, S3BackendConfig{
+		Bucket:         "states",
+		Key:            "prod.tfstate",
+		Region:         "auto",
+		Endpoint:       "https://abc123.r2.cloudflarestorage.com",
+		ForcePathStyle: true,
+		AccessKey:      "env-key",
+		SecretKey:      "env-secret",
+	}, b)
+
+	b = R2BackendConfig{AccountID: "abc123", AccessKey: "key", SecretKey: "secret"}.S3Config()
+	assert.Equal(t, "key", b.AccessKey)
+	assert.Equal(t, "secret", b.SecretKey)
+}
+
+func TestScalewayBackendConfig(t *testing.T) {
+	t.Setenv("SCW_DEFAULT_REGION", "")
+	t.Setenv("SCW_ACCESS_KEY", "SCWKEY")
+	t.Setenv("SCW_SECRET_KEY", "scw-secret")
+
+	b := ScalewayBackendConfig{Bucket: "states", Key: "prod.tfstate"}.S3Config()
+	assert.Equal(t, S3BackendConfig{
+		Bucket:         "states",
+		Key:            "prod.tfstate",
+		Region:         "fr-par",
+		Endpoint:       "https://s3.fr-par.scw.cloud",
+		ForcePathStyle: true,
+		AccessKey:      "SCWKEY",
+		SecretKey:      "scw-secret",
+	}, b)
+
+	t.Setenv("SCW_DEFAULT_REGION", "pl-waw")
+	b = ScalewayBackendConfig{}.S3Config()
+	assert.Equal(t, "https://s3.pl-waw.scw.cloud", b.Endpoint)
+
+	b = ScalewayBackendConfig{Region: "nl-ams", AccessKey: "key"}.S3Config()
+	assert.Equal(t, "nl-ams", b.Region)
+	assert.Equal(t, "https://s3.nl-ams.scw.cloud", b.Endpoint)
+	assert.Equal(t, "key", b.AccessKey)
+}
+
+func TestNewR2TerraformBackendRequiresAccountID(t *testing.T) {
+	_, err := NewR2TerraformBackend(context.Background(), &BackendConfigBlock{
+		BackendName: "r2",
+		BackendType: string(R2),
+		ConfigAttrs: map[string]interface{}{"bucket": "states", "key": "prod.tfstate"},
+	})
+	assert.EqualError(t, err, "r2 backend requires account_id")
+}
+
+// newS3CompatServer serves body for the path style GET of states/prod.tfstate
+func newS3CompatServer(t *testing.T, contentType string, body []byte) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/states/prod.tfstate" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		// set for HEAD requests too, which have no body
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func s3CompatConfig(endpoint string) *BackendConfigBlock {
+	return &BackendConfigBlock{
+		BackendName: "compat",
+		BackendType: string(S3),
+		ConfigAttrs: map[string]interface{}{
+			"bucket":           "states",
+			"key":              "prod.tfstate",
+			"region":           "auto",
+			"endpoint":         endpoint,
+			"force_path_style": true,
+			"access_key":       "key",
+			"secret_key":       "secret",
+		},
+	}
+}
+
+func TestS3BackendCustomEndpoint(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	srv := newS3CompatServer(t, "binary/octet-stream", state)
+
+	b, err := NewBackend(context.Background(), s3CompatConfig(srv.URL))
+	require.NoError(t, err)
+	assert.Equal(t, S3, b.BackendType)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+	assert.WithinDuration(t, time.Now(), b.FetchedAt, time.Minute)
+}
+
+func TestS3BackendRejectsHTML(t *testing.T) {
+	for _, body := range []string{
+		"<!DOCTYPE html><html><body>Bad Gateway</body></html>",
+		"\n  <HTML><body>Login required</body></HTML>",
+	} {
+		srv := newS3CompatServer(t, "application/json", []byte(body))
+		_, err := NewBackend(context.Background(), s3CompatConfig(srv.URL))
+		assert.EqualError(t, err, `s3 object states/prod.tfstate is an HTML page (content type "application/json"), not a terraform state`)
+	}
+}
+
+func TestParseShowJSON(t *testing.T) {
+	data, err := parseAndValidate(context.Background(), strings.NewReader(`{
+  "format_version": "1.2",
+  "terraform_version": "1.5.7",
+  "resource_changes": [
+    {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+     "change": {"actions": ["no-op"], "importing": {"id": "i-0123456789"}}},
+    {"address": "aws_s3_bucket.logs", "mode": "managed", "type": "aws_s3_bucket", "name": "logs",
+     "change": {"actions": ["create"]}}
+  ]
+}`), BackendOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, data.ShowJSON)
+	assert.Equal(t, "1.5.7", data.State.TerraformVersion)
+	require.Len(t, data.ShowJSON.ResourceChanges, 2)
+	assert.Equal(t, &Importing{ID: "i-0123456789"}, data.ShowJSON.ResourceChanges[0].Change.Importing)
+	assert.Nil(t, data.ShowJSON.ResourceChanges[1].Change.Importing)
+
+	data, err = parseAndValidate(context.Background(), strings.NewReader(`{"version": 4, "serial": 1, "lineage": "plain"}`), BackendOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, data.ShowJSON)
+}
+
+func TestParseShowJSONConfiguration(t *testing.T) {
+	data, err := parseAndValidate(context.Background(), strings.NewReader(`{
+  "format_version": "1.2",
+  "configuration": {
+    "provider_config": {
+      "aws": {"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "version_constraint": "~> 4.0"},
+      "module.network:aws": {"name": "aws", "full_name": "registry.terraform.io/hashicorp/aws", "module_address": "module.network"}
+    },
+    "root_module": {}
+  }
+}`), BackendOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, data.ShowJSON.Configuration)
+	assert.Equal(t, map[string]ProviderConfig{
+		"aws":                {Name: "aws", FullName: "registry.terraform.io/hashicorp/aws", VersionConstraint: "~> 4.0"},
+		"module.network:aws": {Name: "aws", FullName: "registry.terraform.io/hashicorp/aws", ModuleAddress: "module.network"},
+	}, data.ShowJSON.Configuration.ProviderConfig)
+}
+
+func TestParseOutputsOnly(t *testing.T) {
+	f, err := os.Open("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	defer f.Close()
+
+	data, err := parseAndValidate(context.Background(), f, BackendOptions{OutputsOnly: true})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(173), data.State.Serial)
+	assert.Empty(t, data.State.Resources)
+	require.Contains(t, data.State.RootOutputs, "foo")
+	assert.JSONEq(t, `"FOO"`, string(data.State.RootOutputs["foo"].ValueRaw))
+	assert.Contains(t, data.State.RootOutputs, "bar")
+
+	_, err = parseAndValidate(context.Background(), strings.NewReader(`{"version": 4, "resources": [{]}`), BackendOptions{OutputsOnly: true})
+	assert.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestParseRawBytes(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+
+	for _, opts := range []BackendOptions{{}, {OutputsOnly: true}} {
+		data, err := parseAndValidate(context.Background(), bytes.NewReader(state), opts)
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(state)), data.RawBytes)
+	}
+}
+
+func TestParseAttributeTransformer(t *testing.T) {
+	var cores interface{}
+	AttributeTransformer = func(resourceType string, attrs map[string]interface{}) map[string]interface{} {
+		if resourceType == "aws_instance" {
+			delete(attrs, "private_ip")
+			attrs["redacted"] = true
+			cores = attrs["cpu_core_count"]
+			// numbers are json.Number, a float64 type assertion would not match
+			if size, ok := attrs["volume_size"].(json.Number); ok {
+				gib, _ := size.Int64()
+				attrs["volume_size"] = gib * 1024
+			}
+		}
+		return attrs
+	}
+	defer func() { AttributeTransformer = nil }()
+
+	data, err := parseAndValidate(context.Background(), strings.NewReader(`{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [
+    {"schema_version": 1, "attributes": {"id": "i-1", "private_ip": "10.0.0.1", "cpu_core_count": 12345678901234567890, "volume_size": 8}}]},
+  {"mode": "managed", "type": "aws_s3_bucket", "name": "logs", "instances": [
+    {"schema_version": 0, "attributes": {"id": "logs", "private_ip": "kept"}}]}
+]}`), BackendOptions{})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "i-1", "redacted": true, "cpu_core_count": 12345678901234567890, "volume_size": 8192}`, string(data.State.Resources[0].Instances[0].AttributesRaw))
+	assert.Equal(t, json.Number("12345678901234567890"), cores)
+	assert.JSONEq(t, `{"id": "logs", "private_ip": "kept"}`, string(data.State.Resources[1].Instances[0].AttributesRaw))
+}
+
+func TestParseCanonicalJSON(t *testing.T) {
+	state := `{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [
+    {"schema_version": 1, "attributes": {"tags": {"Team": "infra", "Env": "prod"}, "id": "i-1",  "cpu_core_count": 12345678901234567890}}]}
+]}`
+	data, err := parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{CanonicalJSON: true})
+	require.NoError(t, err)
+	assert.Equal(t, `{"cpu_core_count":12345678901234567890,"id":"i-1","tags":{"Env":"prod","Team":"infra"}}`, string(data.State.Resources[0].Instances[0].AttributesRaw))
+
+	// the attributes are kept verbatim by default
+	data, err = parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"tags": {"Team": "infra", "Env": "prod"}, "id": "i-1",  "cpu_core_count": 12345678901234567890}`, string(data.State.Resources[0].Instances[0].AttributesRaw))
+}
+
+func TestParseCurrentOnly(t *testing.T) {
+	data, err := parseAndValidate(context.Background(), strings.NewReader(`{"version": 4, "resources": [
+  {"mode": "managed", "type": "aws_instance", "name": "web", "instances": [
+    {"schema_version": 1, "attributes": {"id": "i-2"}},
+    {"schema_version": 1, "deposed": "00000001", "attributes": {"id": "i-1"}}]},
+  {"mode": "managed", "type": "aws_instance", "name": "destroyed", "instances": [
+    {"schema_version": 1, "deposed": "00000002", "attributes": {"id": "i-3"}}]},
+  {"mode": "managed", "type": "aws_instance", "name": "workers", "each": "list", "instances": [
+    {"index_key": 0, "schema_version": 1, "attributes": {"id": "i-5"}},
+    {"index_key": 0, "schema_version": 1, "deposed": "00000003", "attributes": {"id": "i-4"}},
+    {"index_key": 1, "schema_version": 1, "deposed": "00000004", "attributes": {"id": "i-6"}}]}
+]}`), BackendOptions{CurrentOnly: true})
+	require.NoError(t, err)
+	require.Len(t, data.State.Resources[0].Instances, 1)
+	assert.JSONEq(t, `{"id": "i-2"}`, string(data.State.Resources[0].Instances[0].AttributesRaw))
+	require.Len(t, data.State.Resources[1].Instances, 1)
+	assert.Equal(t, "00000002", data.State.Resources[1].Instances[0].Deposed)
+	// the deposed object of [1] is its only object, it is kept next to the current object of [0]
+	require.Len(t, data.State.Resources[2].Instances, 2)
+	assert.JSONEq(t, `{"id": "i-5"}`, string(data.State.Resources[2].Instances[0].AttributesRaw))
+	assert.Equal(t, "00000004", data.State.Resources[2].Instances[1].Deposed)
+	assert.Equal(t, float64(1), data.State.Resources[2].Instances[1].IndexKey)
+}
+
+func TestParseCheckResults(t *testing.T) {
+	data, err := parseAndValidate(context.Background(), strings.NewReader(`{"version": 4, "check_results": [
+  {"object_kind": "check", "config_addr": "check.health", "status": "fail", "objects": [
+    {"object_addr": "check.health", "status": "fail", "failure_messages": ["endpoint returned 503"]}]},
+  {"object_kind": "resource", "config_addr": "aws_instance.web", "status": "pass"}
+]}`), BackendOptions{})
+	require.NoError(t, err)
+	require.Len(t, data.State.CheckResults, 2)
+	assert.Equal(t, []CheckResultsObject{{ObjectAddr: "check.health", Status: "fail", FailureMessages: []string{"endpoint returned 503"}}}, data.State.CheckResults[0].Objects)
+	assert.Equal(t, "pass", data.State.CheckResults[1].Status)
+
+	data, err = parseAndValidate(context.Background(), strings.NewReader(`{"version": 4, "terraform_version": "1.2.9"}`), BackendOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, data.State.CheckResults)
+}
+
+func TestExpandKeyTemplate(t *testing.T) {
+	key, err := expandKeyTemplate("states/${account_id}/${region}/terraform.tfstate", map[string]string{
+		"account_id": "123456789012",
+		"region":     "eu-west-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "states/123456789012/eu-west-1/terraform.tfstate", key)
+
+	_, err = expandKeyTemplate("states/${account}/${role_arn}.tfstate", map[string]string{"role_arn": ""})
+	assert.EqualError(t, err, "key_template references unknown or empty values: account, role_arn")
+}
+
+func TestParseOpenTofuState(t *testing.T) {
+	data, err := parseAndValidate(context.Background(), strings.NewReader(`{
+  "version": 4,
+  "terraform_version": "1.7.2",
+  "serial": 3,
+  "lineage": "tofu",
+  "outputs": {},
+  "resources": [],
+  "check_results": null,
+  "tofu_meta": {"provider_functions": true}
+}`), BackendOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "tofu", data.State.Lineage)
+	require.Contains(t, data.State.Extra, "tofu_meta")
+	assert.JSONEq(t, `{"provider_functions": true}`, string(data.State.Extra["tofu_meta"]))
+
+	_, err = parseAndValidate(context.Background(), strings.NewReader(`{
+  "serial": 3,
+  "lineage": "tofu",
+  "meta": {"key_provider.pbkdf2.main": "eyJzYWx0Ijoi"},
+  "encrypted_data": "ZXhhbXBsZQ==",
+  "encryption_version": "v0"
+}`), BackendOptions{})
+	assert.ErrorIs(t, err, ErrEncryptedState)
+	assert.True(t, IsParseError(err))
+}
+
+func TestReadWithContext(t *testing.T) {
+	data, err := readWithContext(context.Background(), func() ([]byte, error) { return []byte("state"), nil })
+	require.NoError(t, err)
+	assert.Equal(t, "state", string(data))
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = readWithContext(ctx, func() ([]byte, error) {
+		<-unblock
+		return nil, nil
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLocalBackendReadTimeout(t *testing.T) {
+	backend, err := NewLocalTerraformBackend(context.Background(), &BackendConfigBlock{
+		BackendName: "local",
+		ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate", "read_timeout": "10s"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(StateVersion), backend.Data.State.Version)
+}
+
+func TestSupportedBackends(t *testing.T) {
+	backends := SupportedBackends()
+	assert.Subset(t, backends, []string{"local", "s3", "r2", "scaleway", "grpc", "ipfs"})
+	assert.True(t, sort.StringsAreSorted(backends))
+
+	RegisterBackend("static", func(_ context.Context, config *BackendConfigBlock) (*TerraformBackend, error) { return nil, nil })
+	defer delete(backendFactories, "static")
+	assert.Contains(t, SupportedBackends(), "static")
+}
+
+// cancelReader cancels the context once the reader reached the given offset
+type cancelReader struct {
+	r      io.Reader
+	read   int
+	after  int
+	cancel context.CancelFunc
+}
+
+func (c *cancelReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if c.read += n; c.read >= c.after {
+		c.cancel()
+	}
+	return n, err
+}
+
+func TestParseCancelled(t *testing.T) {
+	resources := strings.Repeat(`{"mode": "managed", "type": "aws_vpc", "name": "main", "instances": []},`, 1000)
+	state := `{"version": 4, "resources": [` + strings.TrimSuffix(resources, ",") + `]}`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := &cancelReader{r: iotest.OneByteReader(strings.NewReader(state)), after: len(state) / 2, cancel: cancel}
+	data, err := parseAndValidate(ctx, r, BackendOptions{})
+	assert.Nil(t, data)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, IsParseError(err))
+	assert.Less(t, r.read, len(state))
+
+	data, err = parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{})
+	require.NoError(t, err)
+	assert.Len(t, data.State.Resources, 1000)
+}
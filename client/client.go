@@ -1,6 +1,7 @@
 package client
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -11,7 +12,9 @@ import (
 
 type Client struct {
 	Backends map[string]*TerraformBackend
-	logger   hclog.Logger
+	// RelationshipKeys are the attributes relationships are inferred from, across all backends
+	RelationshipKeys []string
+	logger           hclog.Logger
 
 	// CurrentBackend set by client multiplexer
 	CurrentBackend string
@@ -35,20 +38,100 @@ func Configure(logger hclog.Logger, providerConfig interface{}) (schema.ClientMe
 		return nil, diag.FromError(errors.New("no config were provided"), diag.USER)
 	}
 
+	switch terraformConfig.OnParseError {
+	case "", OnParseErrorFail, OnParseErrorSkip:
+	default:
+		return nil, diag.FromError(fmt.Errorf("invalid on_parse_error value %q", terraformConfig.OnParseError), diag.USER)
+	}
+
+	selector, err := ParseLabelSelector(terraformConfig.LabelSelector)
+	if err != nil {
+		return nil, diag.FromError(err, diag.USER)
+	}
+
+	var dedupePath []string
+	if terraformConfig.DedupeBy != "" {
+		if dedupePath, err = parseDedupeBy(terraformConfig.DedupeBy); err != nil {
+			return nil, diag.FromError(err, diag.USER)
+		}
+	}
+
+	if err := validateRelationshipKeys(terraformConfig.RelationshipKeys); err != nil {
+		return nil, diag.FromError(err, diag.USER)
+	}
+
+	configs, err := expandConfigs(terraformConfig.Config)
+	if err != nil {
+		return nil, diag.FromError(err, diag.USER)
+	}
+
+	// backends of every type are merged in one fetch, keyed by their name
+	names := make(map[string]bool, len(configs))
+	for _, config := range configs {
+		if names[config.BackendName] {
+			return nil, diag.FromError(fmt.Errorf("duplicate backend name %q", config.BackendName), diag.USER)
+		}
+		names[config.BackendName] = true
+	}
+
+	// the sdk doesn't pass a context to Configure, backends are bound by their own timeouts
+	ctx := context.Background()
 	var backends = make(map[string]*TerraformBackend)
-	for _, config := range terraformConfig.Config {
+	for _, config := range configs {
 		config := config
 
-		logger.Info("creating new backend", "type", config.BackendType)
-		// create backend for each backend config
-		if b, err := NewBackend(&config); err == nil { //nolint:revive
+		if !selector.Matches(config.Labels) {
+			logger.Debug("skipping backend not matching label_selector", "name", config.BackendName, "labels", config.Labels)
+			continue
+		}
+		if terraformConfig.StatusOnly {
+			logger.Info("checking backend status", "name", config.BackendName, "type", config.BackendType)
+			b, err := CheckBackendStatus(ctx, &config)
+			if err != nil {
+				return nil, diag.FromError(fmt.Errorf("cannot check %s backend: %w", config.BackendType, err), diag.USER)
+			}
+			if b.Status.Error != "" {
+				logger.Warn("backend state is not readable", "name", b.BackendName, "type", b.BackendType, "reachable", b.Status.Reachable, "error", b.Status.Error)
+			}
 			backends[b.BackendName] = b
-		} else {
+			continue
+		}
+		logger.Info("creating new backend", "name", config.BackendName, "type", config.BackendType)
+		// create backend for each backend config
+		b, err := NewBackend(ctx, &config)
+		if err != nil {
+			if terraformConfig.OnParseError == OnParseErrorSkip && IsParseError(err) {
+				logger.Warn("skipping backend with unparsable state", "name", config.BackendName, "type", config.BackendType, "error", err)
+				continue
+			}
 			return nil, diag.FromError(fmt.Errorf("cannot initialize %s backend: %w", config.BackendType, err), diag.INTERNAL)
 		}
+		if b.Fallback > 0 {
+			logger.Warn("read state from fallback location", "name", b.BackendName, "type", b.BackendType, "fallback", b.Fallback)
+		} else if len(config.Fallbacks) > 0 {
+			logger.Debug("read state from primary location", "name", b.BackendName, "type", b.BackendType)
+		}
+		for _, warning := range b.Data.Warnings {
+			logger.Warn(warning, "name", b.BackendName, "type", b.BackendType)
+		}
+		backends[b.BackendName] = b
+	}
+
+	if len(backends) == 0 {
+		if len(selector) > 0 {
+			return nil, diag.FromError(fmt.Errorf("no backend matches label_selector %q", terraformConfig.LabelSelector), diag.USER)
+		}
+		return nil, diag.FromError(errors.New("all backends were skipped"), diag.USER)
+	}
+
+	// backends checked by status_only have no state to dedupe
+	if dedupePath != nil && !terraformConfig.StatusOnly {
+		removed := dedupeInstances(backends, dedupePath)
+		logger.Info("deduplicated resource instances", "dedupe_by", terraformConfig.DedupeBy, "removed", removed)
 	}
 
 	client := NewTerraformClient(logger, backends)
+	client.RelationshipKeys = terraformConfig.RelationshipKeys
 
 	// Returns the initialized client with requested backends
 	return &client, nil
@@ -68,8 +151,9 @@ func (c *Client) Backend() *TerraformBackend {
 // Sets the current backend to working with
 func (c *Client) withSpecificBackend(backendName string) *Client {
 	return &Client{
-		Backends:       c.Backends,
-		logger:         c.logger,
-		CurrentBackend: backendName,
+		Backends:         c.Backends,
+		RelationshipKeys: c.RelationshipKeys,
+		logger:           c.logger,
+		CurrentBackend:   backendName,
 	}
 }
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeState(t *testing.T, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+	return p
+}
+
+func TestConfigureOnParseError(t *testing.T) {
+	legacy := writeState(t, `{"version": 3, "serial": 1, "lineage": "legacy"}`)
+	cfg := func(onParseError string) *Config {
+		return &Config{
+			OnParseError: onParseError,
+			Config: []BackendConfigBlock{
+				{BackendName: "healthy", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"}},
+				{BackendName: "legacy", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": legacy}},
+			},
+		}
+	}
+
+	_, diags := Configure(hclog.NewNullLogger(), cfg(""))
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "unsupported state version 3")
+
+	meta, diags := Configure(hclog.NewNullLogger(), cfg(OnParseErrorSkip))
+	require.False(t, diags.HasErrors())
+	c := meta.(*Client)
+	assert.Len(t, c.Backends, 1)
+	assert.Contains(t, c.Backends, "healthy")
+
+	_, diags = Configure(hclog.NewNullLogger(), cfg("ignore"))
+	assert.True(t, diags.HasErrors())
+}
+
+func TestConfigureMixedBackends(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	srv := newS3CompatServer(t, "application/json", state)
+
+	RegisterBackend("static", func(_ context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+		return &TerraformBackend{BackendType: "static", BackendName: config.BackendName, Data: &TerraformData{}}, nil
+	})
+	defer delete(backendFactories, "static")
+
+	remote := s3CompatConfig(srv.URL)
+	meta, diags := Configure(hclog.NewNullLogger(), &Config{
+		Config: []BackendConfigBlock{
+			{BackendName: "mylocal", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"}},
+			*remote,
+			{BackendName: "custom", BackendType: "static"},
+		},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	c := meta.(*Client)
+	require.Len(t, c.Backends, 3)
+	assert.Equal(t, LOCAL, c.Backends["mylocal"].BackendType)
+	assert.Equal(t, S3, c.Backends["compat"].BackendType)
+	assert.Equal(t, BackendType("static"), c.Backends["custom"].BackendType)
+	assert.Len(t, BackendMultiplex(c), 3)
+
+	_, diags = Configure(hclog.NewNullLogger(), &Config{
+		Config: []BackendConfigBlock{
+			{BackendName: "dup", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"}},
+			{BackendName: "dup", BackendType: "static"},
+		},
+	})
+	assert.Contains(t, diags.Error(), `duplicate backend name "dup"`)
+
+	_, diags = Configure(hclog.NewNullLogger(), &Config{
+		Config: []BackendConfigBlock{{BackendName: "unknown", BackendType: "consul"}},
+	})
+	assert.Contains(t, diags.Error(), `unsupported backend "consul"`)
+}
+
+func TestConfigureLabelSelector(t *testing.T) {
+	cfg := func(selector string) *Config {
+		return &Config{
+			LabelSelector: selector,
+			Config: []BackendConfigBlock{
+				{BackendName: "prod", BackendType: "local", Labels: map[string]string{"env": "prod"}, ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"}},
+				{BackendName: "staging", BackendType: "local", Labels: map[string]string{"env": "staging"}, ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"}},
+			},
+		}
+	}
+
+	meta, diags := Configure(hclog.NewNullLogger(), cfg("env=prod"))
+	require.False(t, diags.HasErrors(), diags.Error())
+	c := meta.(*Client)
+	require.Len(t, c.Backends, 1)
+	assert.Equal(t, map[string]string{"env": "prod"}, c.Backends["prod"].Labels)
+
+	_, diags = Configure(hclog.NewNullLogger(), cfg("env=dev"))
+	assert.Contains(t, diags.Error(), `no backend matches label_selector "env=dev"`)
+
+	_, diags = Configure(hclog.NewNullLogger(), cfg("env in prod"))
+	assert.Contains(t, diags.Error(), "invalid label_selector")
+}
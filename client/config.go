@@ -1,7 +1,27 @@
 package client
 
+const (
+	OnParseErrorFail = "fail"
+	OnParseErrorSkip = "skip"
+)
+
 type Config struct {
 	Config []BackendConfigBlock `yaml:"config"`
+	// OnParseError controls what happens when a backend state can't be parsed, for example
+	// due to an unsupported state version. "fail" (default) aborts, "skip" logs a warning
+	// and continues with the remaining backends.
+	OnParseError string `yaml:"on_parse_error,omitempty"`
+	// LabelSelector only fetches the backends whose labels match it, for example team=platform,env in (prod,staging)
+	LabelSelector string `yaml:"label_selector,omitempty"`
+	// DedupeBy collapses the resource instances of different backends sharing the value of an attribute, such as
+	// attributes.arn, into the instance of the state with the highest serial
+	DedupeBy string `yaml:"dedupe_by,omitempty"`
+	// StatusOnly checks whether the state of every backend exists, with a stat, HEAD or HeadObject request where
+	// the backend type supports it, without fetching the states. Only the tf_backend_status table is filled.
+	StatusOnly bool `yaml:"status_only,omitempty"`
+	// RelationshipKeys are the attributes, such as vpc_id, subnet_id or security_group_ids, relating the resource
+	// instances to the instances whose id they hold, in the tf_resource_relationships table
+	RelationshipKeys []string `yaml:"relationship_keys,omitempty"`
 }
 
 func (Config) Example() string {
@@ -16,5 +36,10 @@ config:
     key: terraform.tfstate
     region: us-east-1
     role_arn: ""
+# on_parse_error: fail # or skip, to ignore backends with unparsable state
+# label_selector: env=prod # only fetch the backends with matching labels
+# dedupe_by: attributes.arn # keep one instance of the resources found in several backends
+# status_only: true # only check that the states exist, without fetching them
+# relationship_keys: [vpc_id, subnet_id, security_group_ids] # relate the instances by the ids of these attributes
 `
 }
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// credentialCacheWindow is how long before their expiry cached credentials stop being reused
+const credentialCacheWindow = 5 * time.Minute
+
+// sharedCredentials holds the assumed role credentials of the process, so backends assuming the same role
+// retrieve them once and refresh them together instead of each holding credentials expiring mid-run
+var sharedCredentials = struct {
+	sync.Mutex
+	caches map[string]*aws.CredentialsCache
+}{caches: make(map[string]*aws.CredentialsCache)}
+
+// sharedCredentialsCache returns the credentials cache of key, creating it from newProvider on first use. The
+// cache is safe for concurrent use and refreshes the credentials window before they expire.
+func sharedCredentialsCache(key string, window time.Duration, newProvider func() (aws.CredentialsProvider, error)) (*aws.CredentialsCache, error) {
+	sharedCredentials.Lock()
+	defer sharedCredentials.Unlock()
+	if cache, ok := sharedCredentials.caches[key]; ok {
+		return cache, nil
+	}
+	provider, err := newProvider()
+	if err != nil {
+		return nil, err
+	}
+	cache := aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+		o.ExpiryWindow = window
+	})
+	sharedCredentials.caches[key] = cache
+	return cache, nil
+}
+
+// cachedCredentials is the cache file format of the AWS CLI
+type cachedCredentials struct {
+	Credentials struct {
+		AccessKeyId     string    `json:"AccessKeyId"`
+		SecretAccessKey string    `json:"SecretAccessKey"`
+		SessionToken    string    `json:"SessionToken"`
+		Expiration      time.Time `json:"Expiration"`
+	} `json:"Credentials"`
+}
+
+// fileCacheProvider reuses the credentials of the wrapped provider across runs by caching them to disk
+// until they expire, in the same directory and format as the AWS CLI (~/.aws/cli/cache). It is meant to be
+// wrapped in an aws.CredentialsCache, which keeps the credentials in memory within a run.
+type fileCacheProvider struct {
+	provider aws.CredentialsProvider
+	path     string
+	// window is how long before their expiry cached credentials stop being reused, the refresh window of the
+	// in-memory cache, so credentials read from disk are not kept past the time they would be refreshed
+	window time.Duration
+}
+
+// newFileCacheProvider caches the credentials of an assume role provider, dir defaults to the AWS CLI cache and
+// window to credentialCacheWindow
+func newFileCacheProvider(provider aws.CredentialsProvider, roleArn string, dir string, window time.Duration) (*fileCacheProvider, error) {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("cannot locate credential cache: %w", err)
+		}
+		dir = filepath.Join(home, ".aws", "cli", "cache")
+	}
+	if window == 0 {
+		window = credentialCacheWindow
+	}
+	return &fileCacheProvider{
+		provider: provider,
+		path:     filepath.Join(dir, credentialCacheKey(roleArn)+".json"),
+		window:   window,
+	}, nil
+}
+
+// credentialCacheKey matches the cache key the AWS CLI uses for assuming the role without extra arguments
+func credentialCacheKey(roleArn string) string {
+	args, _ := json.Marshal(map[string]string{"RoleArn": roleArn})
+	sum := sha1.Sum(args)
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *fileCacheProvider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if cached, ok := p.load(); ok {
+		return aws.Credentials{
+			AccessKeyID:     cached.Credentials.AccessKeyId,
+			SecretAccessKey: cached.Credentials.SecretAccessKey,
+			SessionToken:    cached.Credentials.SessionToken,
+			Source:          "FileCacheProvider",
+			CanExpire:       true,
+			Expires:         cached.Credentials.Expiration,
+		}, nil
+	}
+
+	value, err := p.provider.Retrieve(ctx)
+	if err != nil {
+		return value, err
+	}
+	if !value.CanExpire {
+		// without an expiry the credentials can't be safely reused
+		return value, nil
+	}
+
+	var cached cachedCredentials
+	cached.Credentials.AccessKeyId = value.AccessKeyID
+	cached.Credentials.SecretAccessKey = value.SecretAccessKey
+	cached.Credentials.SessionToken = value.SessionToken
+	cached.Credentials.Expiration = value.Expires.UTC()
+	// failing to write the cache only costs a role assumption on the next run
+	_ = p.store(cached)
+	return value, nil
+}
+
+// load returns the cached credentials if they are valid beyond the window
+func (p *fileCacheProvider) load() (*cachedCredentials, bool) {
+	b, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, false
+	}
+	var cached cachedCredentials
+	if err := json.Unmarshal(b, &cached); err != nil || cached.Credentials.AccessKeyId == "" {
+		return nil, false
+	}
+	if time.Now().Add(p.window).After(cached.Credentials.Expiration) {
+		return nil, false
+	}
+	return &cached, true
+}
+
+func (p *fileCacheProvider) store(cached cachedCredentials) error {
+	b, err := json.Marshal(cached)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(p.path, b, 0o600)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingProvider struct {
+	mu     sync.Mutex
+	calls  int
+	expiry time.Duration
+}
+
+func (p *countingProvider) Retrieve(context.Context) (aws.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	return aws.Credentials{
+		AccessKeyID:     "AKIA",
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		CanExpire:       true,
+		Expires:         time.Now().Add(p.expiry),
+	}, nil
+}
+
+func TestFileCacheProvider(t *testing.T) {
+	dir := t.TempDir()
+	const role = "arn:aws:iam::123456789012:role/state"
+	inner := &countingProvider{expiry: time.Hour}
+
+	provider, err := newFileCacheProvider(inner, role, dir, 0)
+	require.NoError(t, err)
+	value, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIA", value.AccessKeyID)
+	assert.Equal(t, 1, inner.calls)
+	assert.FileExists(t, filepath.Join(dir, credentialCacheKey(role)+".json"))
+
+	// a later run reuses the cached credentials
+	provider, err = newFileCacheProvider(inner, role, dir, 0)
+	require.NoError(t, err)
+	value, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token", value.SessionToken)
+	assert.Equal(t, "FileCacheProvider", value.Source)
+	assert.Equal(t, 1, inner.calls)
+	assert.False(t, value.Expired())
+
+	// credentials about to expire are not reused
+	inner = &countingProvider{expiry: time.Minute}
+	provider, err = newFileCacheProvider(inner, "arn:aws:iam::123456789012:role/other", dir, 0)
+	require.NoError(t, err)
+	_, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	_, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+
+	// the refresh window of the backend applies to the cached credentials
+	const windowed = "arn:aws:iam::123456789012:role/windowed"
+	inner = &countingProvider{expiry: 20 * time.Minute}
+	provider, err = newFileCacheProvider(inner, windowed, dir, 0)
+	require.NoError(t, err)
+	_, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	provider, err = newFileCacheProvider(inner, windowed, dir, 0)
+	require.NoError(t, err)
+	_, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, inner.calls)
+	provider, err = newFileCacheProvider(inner, windowed, dir, 30*time.Minute)
+	require.NoError(t, err)
+	_, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCredentialCacheKey(t *testing.T) {
+	// sha1 of {"RoleArn":"arn:aws:iam::123456789012:role/state"}, as computed by the AWS CLI
+	assert.Equal(t, "cd4a4d6858408c61e1ba5e72335b57f09a1215fe", credentialCacheKey("arn:aws:iam::123456789012:role/state"))
+}
+
+func TestSharedCredentialsCache(t *testing.T) {
+	inner := &countingProvider{expiry: time.Hour}
+	newProvider := func() (aws.CredentialsProvider, error) { return inner, nil }
+
+	first, err := sharedCredentialsCache(t.Name(), credentialCacheWindow, newProvider)
+	require.NoError(t, err)
+	second, err := sharedCredentialsCache(t.Name(), credentialCacheWindow, func() (aws.CredentialsProvider, error) {
+		t.Fatal("the provider of a shared role is created once")
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// backends fetched concurrently assume the role once
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := second.Retrieve(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, inner.calls)
+
+	// credentials within the refresh window are refreshed
+	inner.expiry = time.Minute
+	first.Invalidate()
+	_, err = first.Retrieve(context.Background())
+	require.NoError(t, err)
+	_, err = first.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, inner.calls)
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+)
+
+// stateDocument is either a terraform or OpenTofu state, or the output of `terraform show -json`
+type stateDocument struct {
+	State
+	ShowJSON
+	// EncryptedData is set by OpenTofu state encryption in place of the state
+	EncryptedData json.RawMessage
+}
+
+// fields maps the known top level keys of the document to their destination
+func (d *stateDocument) fields() map[string]interface{} {
+	return map[string]interface{}{
+		"version":           &d.State.Version,
+		"terraform_version": &d.State.TerraformVersion,
+		"serial":            &d.State.Serial,
+		"lineage":           &d.State.Lineage,
+		"outputs":           &d.State.RootOutputs,
+		"resources":         &d.State.Resources,
+		"check_results":     &d.State.CheckResults,
+		"format_version":    &d.FormatVersion,
+		"resource_changes":  &d.ResourceChanges,
+		"configuration":     &d.Configuration,
+		"values":            &d.Values,
+		"planned_values":    &d.PlannedValues,
+		"encrypted_data":    &d.EncryptedData,
+	}
+}
+
+// decodeDocument decodes the top level JSON object read by dec key by key. The values of skipped keys are
+// streamed through without being buffered, unknown keys of states are kept in State.Extra. Resources are
+// decoded one by one and decoding stops with the error of ctx once it is done.
+func decodeDocument(ctx context.Context, dec *json.Decoder, doc *stateDocument, skip []string) error {
+	if tok, err := dec.Token(); err != nil {
+		return err
+	} else if tok != json.Delim('{') {
+		return errors.New("expected a JSON object")
+	}
+	fields := doc.fields()
+	for dec.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		dst, known := fields[key]
+		switch {
+		case contains(skip, key), !known && doc.FormatVersion != "":
+			// show -json holds a lot more than what is used, extra keys are only kept for states
+			err = skipValue(dec)
+		case key == "resources":
+			err = decodeResources(ctx, dec, &doc.State.Resources)
+		case known:
+			err = dec.Decode(dst)
+		default:
+			var raw json.RawMessage
+			if err = dec.Decode(&raw); err == nil {
+				if doc.State.Extra == nil {
+					doc.State.Extra = make(map[string]json.RawMessage)
+				}
+				doc.State.Extra[key] = raw
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if doc.FormatVersion != "" {
+		doc.State.Extra = nil
+	}
+	return nil
+}
+
+// decodeResources decodes the resources array element by element, checking ctx in between
+func decodeResources(ctx context.Context, dec *json.Decoder, resources *[]Resource) error {
+	tok, err := dec.Token()
+	if err != nil || tok == nil {
+		// a null value leaves the resources empty
+		return err
+	}
+	if tok != json.Delim('[') {
+		return errors.New("expected a JSON array of resources")
+	}
+	*resources = []Resource{}
+	for dec.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var resource Resource
+		if err := dec.Decode(&resource); err != nil {
+			return err
+		}
+		*resources = append(*resources, resource)
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// skipValue reads the next JSON value from dec token by token and discards it
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const dedupeAttributesPrefix = "attributes."
+
+// parseDedupeBy returns the attribute path of a dedupe_by value such as attributes.arn
+func parseDedupeBy(dedupeBy string) ([]string, error) {
+	if !strings.HasPrefix(dedupeBy, dedupeAttributesPrefix) || len(dedupeBy) == len(dedupeAttributesPrefix) {
+		return nil, fmt.Errorf("invalid dedupe_by %q: expected an attribute path, such as attributes.arn", dedupeBy)
+	}
+	path := strings.Split(strings.TrimPrefix(dedupeBy, dedupeAttributesPrefix), ".")
+	for _, key := range path {
+		if key == "" {
+			return nil, fmt.Errorf("invalid dedupe_by %q: empty attribute name", dedupeBy)
+		}
+	}
+	return path, nil
+}
+
+// dedupeInstances removes the resource instances sharing their resource type and the value of the attribute
+// path with an instance of another backend, keeping the instance of the state with the highest serial, or of
+// the first backend by name on equal serials. Instances without the attribute are kept, resources left without
+// instances are removed. It returns the number of removed instances.
+func dedupeInstances(backends map[string]*TerraformBackend, path []string) int {
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	// the kept instance is the first seen, so backends are visited by decreasing serial
+	sort.Slice(names, func(i, j int) bool {
+		a, b := backends[names[i]].Data.State.Serial, backends[names[j]].Data.State.Serial
+		if a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+
+	owners := make(map[string]string)
+	removed := 0
+	for _, name := range names {
+		state := &backends[name].Data.State
+		resources := state.Resources[:0]
+		for _, resource := range state.Resources {
+			if len(resource.Instances) == 0 {
+				resources = append(resources, resource)
+				continue
+			}
+			instances := make([]Instance, 0, len(resource.Instances))
+			for _, instance := range resource.Instances {
+				value, ok := dedupeKey(instance, path)
+				if ok {
+					// values such as ids are only unique within a resource type
+					key := resource.Type + "\x00" + value
+					if owner, seen := owners[key]; seen && owner != name {
+						removed++
+						continue
+					}
+					owners[key] = name
+				}
+				instances = append(instances, instance)
+			}
+			if len(instances) > 0 {
+				resource.Instances = instances
+				resources = append(resources, resource)
+			}
+		}
+		state.Resources = resources
+	}
+	return removed
+}
+
+// dedupeKey returns the value of the attribute path of the instance, false when it has no such scalar attribute
+func dedupeKey(instance Instance, path []string) (string, bool) {
+	if len(instance.AttributesRaw) == 0 {
+		return "", false
+	}
+	var value interface{}
+	if err := json.Unmarshal(instance.AttributesRaw, &value); err != nil {
+		return "", false
+	}
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		value = object[key]
+	}
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case float64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupeInstances(t *testing.T) {
+	instance := func(attrs string) Instance { return Instance{AttributesRaw: json.RawMessage(attrs)} }
+	backend := func(serial uint64, resources ...Resource) *TerraformBackend {
+		return &TerraformBackend{Data: &TerraformData{State: State{Serial: serial, Resources: resources}}}
+	}
+	backends := map[string]*TerraformBackend{
+		"old": backend(3,
+			Resource{Type: "aws_s3_bucket", Name: "logs", Instances: []Instance{instance(`{"arn": "arn:aws:s3:::logs"}`)}},
+			Resource{Type: "aws_iam_role", Name: "ci", Instances: []Instance{instance(`{"arn": "arn:aws:iam::1:role/ci"}`), instance(`{"id": "no-arn"}`)}},
+			Resource{Type: "aws_vpc", Name: "empty"},
+		),
+		"new": backend(7,
+			Resource{Type: "aws_s3_bucket", Name: "logs", Instances: []Instance{instance(`{"arn": "arn:aws:s3:::logs"}`)}},
+			Resource{Type: "aws_iam_role", Name: "ci", Instances: []Instance{instance(`{"arn": "arn:aws:iam::1:role/ci"}`)}},
+		),
+		"also-new": backend(7,
+			Resource{Type: "aws_s3_bucket", Name: "shared", Instances: []Instance{instance(`{"arn": "arn:aws:s3:::logs"}`)}},
+		),
+	}
+
+	path, err := parseDedupeBy("attributes.arn")
+	require.NoError(t, err)
+	assert.Equal(t, 3, dedupeInstances(backends, path))
+
+	// on equal serials the first backend by name keeps the instance
+	assert.Len(t, backends["also-new"].Data.State.Resources, 1)
+	require.Len(t, backends["new"].Data.State.Resources, 1)
+	assert.Equal(t, "aws_iam_role", backends["new"].Data.State.Resources[0].Type)
+	old := backends["old"].Data.State.Resources
+	require.Len(t, old, 2)
+	assert.Equal(t, "ci", old[0].Name)
+	assert.JSONEq(t, `{"id": "no-arn"}`, string(old[0].Instances[0].AttributesRaw))
+	assert.Equal(t, "empty", old[1].Name)
+}
+
+func TestParseDedupeBy(t *testing.T) {
+	path, err := parseDedupeBy("attributes.tags.Name")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tags", "Name"}, path)
+
+	_, err = parseDedupeBy("arn")
+	assert.ErrorContains(t, err, "expected an attribute path")
+	_, err = parseDedupeBy("attributes.tags..Name")
+	assert.ErrorContains(t, err, "empty attribute name")
+}
@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ExecTransportLocal runs the command on the host of the provider, it is the default transport
+	ExecTransportLocal = "local"
+	// ExecTransportSSH runs the command on a remote host through the ssh binary of the system
+	ExecTransportSSH = "ssh"
+
+	defaultExecCommand = "terraform state pull"
+	defaultExecTimeout = 5 * time.Minute
+	// maxExecStderr bounds the stderr kept for the error message of a failed command
+	maxExecStderr = 4096
+)
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ExecBackendConfig reads the state from the stdout of a command, for states which can only be pulled by
+// running terraform where they are reachable
+type ExecBackendConfig struct {
+	Transport string `yaml:"transport,omitempty"`
+	// Command is run by the shell of the host, it defaults to terraform state pull
+	Command string `yaml:"command,omitempty"`
+	// Dir is the directory the command is run in, such as the terraform root module
+	Dir string `yaml:"dir,omitempty"`
+	// Env is set for the command on top of the environment of the provider, which local commands inherit
+	Env map[string]string `yaml:"env,omitempty"`
+	// Host, User, Port and IdentityFile are passed to ssh, options of the ssh config of the system apply
+	Host         string `yaml:"host,omitempty"`
+	User         string `yaml:"user,omitempty"`
+	Port         int    `yaml:"port,omitempty"`
+	IdentityFile string `yaml:"identity_file,omitempty"`
+	// SSHOptions are passed as -o options, for example StrictHostKeyChecking=yes
+	SSHOptions     []string      `yaml:"ssh_options,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	BackendOptions `yaml:",inline"`
+}
+
+func (b ExecBackendConfig) Validate() error {
+	// commands are run by a POSIX shell, in a process group killed on timeout, which windows lacks
+	if runtime.GOOS == "windows" {
+		return errors.New("exec backend is not supported on windows, its commands require a POSIX shell")
+	}
+	switch b.Transport {
+	case "", ExecTransportLocal:
+	case ExecTransportSSH:
+		if b.Host == "" {
+			return errors.New("ssh transport requires host")
+		}
+		if strings.HasPrefix(b.Host, "-") {
+			return fmt.Errorf("invalid host %q", b.Host)
+		}
+	default:
+		return fmt.Errorf("unsupported exec transport %q", b.Transport)
+	}
+	if b.Port < 0 || b.Port > 65535 {
+		return fmt.Errorf("invalid port %d", b.Port)
+	}
+	for name := range b.Env {
+		if !envNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid env name %q", name)
+		}
+	}
+	if b.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	return b.BackendOptions.Validate()
+}
+
+func (b ExecBackendConfig) command() string {
+	if b.Command == "" {
+		return defaultExecCommand
+	}
+	return b.Command
+}
+
+// remoteCommand returns the script run by the shell of the remote host, with Env exported and in Dir when they
+// are set. It is sent over stdin, so the values of Env are not part of the arguments of ssh or of the remote shell.
+func (b ExecBackendConfig) remoteCommand() string {
+	command := b.command()
+	if b.Dir != "" {
+		command = "cd " + shellQuote(b.Dir) + " && " + command
+	}
+	if len(b.Env) > 0 {
+		names := make([]string, 0, len(b.Env))
+		for name := range b.Env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		exports := make([]string, 0, len(names))
+		for _, name := range names {
+			exports = append(exports, name+"="+shellQuote(b.Env[name]))
+		}
+		command = "export " + strings.Join(exports, " ") + "; " + command
+	}
+	return command + "\n"
+}
+
+// sshArgs returns the arguments of ssh running a shell on the host, which reads the script of remoteCommand from
+// stdin, without prompting for passwords or host keys
+func (b ExecBackendConfig) sshArgs() []string {
+	args := []string{"-o", "BatchMode=yes"}
+	if b.Port != 0 {
+		args = append(args, "-p", strconv.Itoa(b.Port))
+	}
+	if b.User != "" {
+		args = append(args, "-l", b.User)
+	}
+	if b.IdentityFile != "" {
+		args = append(args, "-i", b.IdentityFile)
+	}
+	for _, option := range b.SSHOptions {
+		args = append(args, "-o", option)
+	}
+	return append(args, "--", b.Host, "sh", "-s")
+}
+
+// shellQuote quotes s as a single word of a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// NewExecTerraformBackend reads the state from the stdout of a command, run locally or over ssh
+func NewExecTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b ExecBackendConfig
+	if err := decodeBackendConfig(config, EXEC, &b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultExecTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	var err error
+	var state []byte
+	if b.Transport == ExecTransportSSH {
+		// the script exporting the values of env is sent over stdin, only the command is reported in errors
+		cmd := exec.Command("ssh", b.sshArgs()...)
+		cmd.Stdin = strings.NewReader(b.remoteCommand())
+		state, err = runCommand(ctx, cmd, b.command())
+		if err != nil {
+			return nil, fmt.Errorf("cannot pull tfstate from %s: %w", b.Host, err)
+		}
+	} else {
+		cmd := exec.Command("sh", "-c", b.command())
+		cmd.Dir = b.Dir
+		cmd.Env = os.Environ()
+		for name, value := range b.Env {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+		state, err = runCommand(ctx, cmd, b.command())
+		if err != nil {
+			return nil, fmt.Errorf("cannot pull tfstate: %w", err)
+		}
+	}
+
+	terraformData, err := parseAndValidate(ctx, bytes.NewReader(state), b.BackendOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TerraformBackend{
+		BackendType: EXEC,
+		BackendName: config.BackendName,
+		Data:        terraformData,
+	}, nil
+}
+
+// runCommand runs cmd and returns its stdout, the error of a failed command carries its stderr. command is the
+// command line reported in errors. The output is kept in memory only, as the state holds secrets.
+func runCommand(ctx context.Context, cmd *exec.Cmd, command string) ([]byte, error) {
+	var stdout bytes.Buffer
+	stderr := &limitedBuffer{limit: maxExecStderr}
+	cmd.Stdout = &stdout
+	cmd.Stderr = stderr
+	// the command runs in its own process group, which is killed as a whole when ctx is done, so the processes
+	// it started don't keep its output open past the timeout
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("command %q: %w", command, err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+
+	if err := cmd.Wait(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("command %q: %w", command, ctxErr)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("command %q: %w: %s", command, err, msg)
+		}
+		return nil, fmt.Errorf("command %q: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest. The buffer isn't embedded, its
+// ReadFrom would be used by io.Copy in place of Write.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	// the discarded bytes are reported as written, a short write would fail the command
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSSH puts an ssh script first in PATH which records its arguments, and its stdin in the stdin file next to
+// them, and runs the given shell script
+func fakeSSH(t *testing.T, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	content := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + shellQuote(argsFile) + "\ncat > " + shellQuote(filepath.Join(dir, "stdin")) +
+		"\n" + script + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ssh"), []byte(content), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return argsFile
+}
+
+func TestExecBackendSSH(t *testing.T) {
+	state, err := filepath.Abs("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	argsFile := fakeSSH(t, "cat "+shellQuote(state))
+
+	b, err := NewBackend(context.Background(), &BackendConfigBlock{
+		BackendName: "bastion",
+		BackendType: string(EXEC),
+		ConfigAttrs: map[string]interface{}{
+			"transport":     "ssh",
+			"host":          "bastion.internal",
+			"user":          "deploy",
+			"port":          2222,
+			"identity_file": "~/.ssh/deploy",
+			"dir":           "/srv/infra/it's network",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, EXEC, b.BackendType)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	args, err := os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"-o", "BatchMode=yes", "-p", "2222", "-l", "deploy", "-i", "~/.ssh/deploy",
+		"--", "bastion.internal", "sh", "-s",
+	}, strings.Split(strings.TrimSuffix(string(args), "\n"), "\n"))
+	script, err := os.ReadFile(filepath.Join(filepath.Dir(argsFile), "stdin"))
+	require.NoError(t, err)
+	assert.Equal(t, "cd '/srv/infra/it'\\''s network' && terraform state pull\n", string(script))
+
+	// env is exported by the remote shell, from the script sent over stdin
+	_, err = NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"transport": "ssh", "host": "bastion", "env": map[string]interface{}{"TF_WORKSPACE": "prod", "A": "1"}},
+	})
+	require.NoError(t, err)
+	script, err = os.ReadFile(filepath.Join(filepath.Dir(argsFile), "stdin"))
+	require.NoError(t, err)
+	assert.Equal(t, "export A='1' TF_WORKSPACE='prod'; terraform state pull\n", string(script))
+	args, err = os.ReadFile(argsFile)
+	require.NoError(t, err)
+	assert.NotContains(t, string(args), "prod")
+}
+
+func TestExecBackendSSHArgsWithoutEnv(t *testing.T) {
+	// the values of env are credentials, they must not be visible in the process list
+	b := ExecBackendConfig{Transport: ExecTransportSSH, Host: "bastion", Dir: "/srv/infra", Env: map[string]string{
+		"AWS_SECRET_ACCESS_KEY": "wJalrXUtnFEMI/K7MDENG", "TF_TOKEN": "s3cr3t-token",
+	}}
+	args := strings.Join(b.sshArgs(), " ")
+	for name, value := range b.Env {
+		assert.NotContains(t, args, value)
+		assert.NotContains(t, args, name)
+	}
+	assert.Contains(t, b.remoteCommand(), "TF_TOKEN='s3cr3t-token'")
+}
+
+func TestExecBackendLocal(t *testing.T) {
+	t.Setenv("TF_STATE_TEST_INHERITED", "terraform.tfstate")
+	b, err := NewBackend(context.Background(), &BackendConfigBlock{
+		BackendName: "script",
+		BackendType: string(EXEC),
+		ConfigAttrs: map[string]interface{}{
+			"command": `cat "$STATE_DIR/$TF_STATE_TEST_INHERITED"`,
+			"dir":     "..",
+			"env":     map[string]interface{}{"STATE_DIR": "examples"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	_, err = NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"command": "echo 'Error: No state file was found!' >&2; exit 1"},
+	})
+	assert.EqualError(t, err, `cannot pull tfstate: command "echo 'Error: No state file was found!' >&2; exit 1": exit status 1: Error: No state file was found!`)
+
+	// the timeout isn't held up by the processes the command started
+	start := time.Now()
+	_, err = NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"command": "sleep 10; echo done", "timeout": "100ms"},
+	})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestExecBackendSSHFailure(t *testing.T) {
+	fakeSSH(t, "echo 'Permission denied (publickey).' >&2\nexit 255")
+
+	_, err := NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"transport": "ssh", "host": "bastion.internal", "command": "tf state pull"},
+	})
+	assert.EqualError(t, err, `cannot pull tfstate from bastion.internal: command "tf state pull": exit status 255: Permission denied (publickey).`)
+
+	// the exported values of env, such as credentials, are not part of the error
+	_, err = NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"transport": "ssh", "host": "bastion.internal", "env": map[string]interface{}{"TF_TOKEN": "s3cr3t-token"}},
+	})
+	assert.ErrorContains(t, err, `command "terraform state pull"`)
+	assert.NotContains(t, err.Error(), "s3cr3t-token")
+}
+
+func TestExecBackendNoTempFiles(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	b, err := NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"command": "cat examples/terraform.tfstate", "dir": ".."},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	// the stderr kept for the error is bounded
+	_, err = NewExecTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"command": "head -c 100000 /dev/zero | tr '\\0' x >&2; exit 1"},
+	})
+	require.Error(t, err)
+	assert.Less(t, len(err.Error()), maxExecStderr+200)
+}
+
+func TestExecBackendConfigValidate(t *testing.T) {
+	assert.NoError(t, ExecBackendConfig{}.Validate())
+	assert.EqualError(t, ExecBackendConfig{Env: map[string]string{"TF-DIR": "x"}}.Validate(), `invalid env name "TF-DIR"`)
+	assert.EqualError(t, ExecBackendConfig{Transport: "telnet"}.Validate(), `unsupported exec transport "telnet"`)
+	assert.EqualError(t, ExecBackendConfig{Transport: "ssh"}.Validate(), "ssh transport requires host")
+	assert.EqualError(t, ExecBackendConfig{Transport: "ssh", Host: "-oProxyCommand=x"}.Validate(), `invalid host "-oProxyCommand=x"`)
+	assert.NoError(t, ExecBackendConfig{Transport: "ssh", Host: "bastion"}.Validate())
+}
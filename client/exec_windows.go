@@ -0,0 +1,14 @@
+//go:build windows
+// +build windows
+
+package client
+
+import "os/exec"
+
+// setProcessGroup is a no-op, the exec backend is rejected on windows by its Validate
+func setProcessGroup(*exec.Cmd) {}
+
+// killProcessGroup kills the command only, the exec backend is rejected on windows by its Validate
+func killProcessGroup(cmd *exec.Cmd) {
+	_ = cmd.Process.Kill()
+}
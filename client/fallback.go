@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatusError is the unexpected status of the response of an http backend
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e httpStatusError) Error() string {
+	return e.Status
+}
+
+// IsUnavailableError reports whether err was caused by a state that couldn't be fetched because its location
+// is missing or unreachable, as opposed to a state that could be fetched but not used
+func IsUnavailableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, gridfs.ErrFileNotFound) {
+		return true
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var noSuchKey *types.NoSuchKey
+	var noSuchBucket *types.NoSuchBucket
+	var bucketNotFound manager.BucketNotFound
+	if errors.As(err, &noSuchKey) || errors.As(err, &noSuchBucket) || errors.As(err, &bucketNotFound) {
+		return true
+	}
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		return unavailableStatus(responseErr.HTTPStatusCode())
+	}
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		return unavailableStatus(statusErr.StatusCode)
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) {
+		switch grpcErr.GRPCStatus().Code() {
+		case codes.NotFound, codes.Unavailable, codes.DeadlineExceeded:
+			return true
+		}
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// unavailableStatus reports whether the http status means the state is missing, or the server can't serve it
+func unavailableStatus(code int) bool {
+	return code == http.StatusNotFound || code >= http.StatusInternalServerError
+}
+
+// fallbackConfig returns the config block of the i-th fallback. A fallback of the same backend type overrides
+// the attributes of the primary location, one of another type replaces them.
+func (c *BackendConfigBlock) fallbackConfig(i int) BackendConfigBlock {
+	fallback := c.Fallbacks[i]
+	b := BackendConfigBlock{
+		BackendName: c.BackendName,
+		BackendType: c.BackendType,
+		Labels:      c.Labels,
+		ConfigAttrs: make(map[string]interface{}, len(c.ConfigAttrs)+len(fallback)),
+	}
+	if backendType, ok := fallback["backend"].(string); ok && backendType != c.BackendType {
+		b.BackendType = backendType
+	} else {
+		for k, v := range c.ConfigAttrs {
+			b.ConfigAttrs[k] = v
+		}
+	}
+	for k, v := range fallback {
+		if k != "backend" {
+			b.ConfigAttrs[k] = v
+		}
+	}
+	return b
+}
+
+// newBackendWithFallbacks creates the backend from the primary location, trying the fallbacks in order while
+// the state is unavailable. When every location fails the error of the primary location is returned.
+func newBackendWithFallbacks(ctx context.Context, cfg *BackendConfigBlock) (*TerraformBackend, error) {
+	backend, primaryErr := newBackend(ctx, cfg)
+	if primaryErr == nil || len(cfg.Fallbacks) == 0 || !IsUnavailableError(primaryErr) {
+		return backend, primaryErr
+	}
+	failures := []string{fmt.Sprintf("primary location: %s", primaryErr)}
+	for i := range cfg.Fallbacks {
+		fallback := cfg.fallbackConfig(i)
+		backend, err := newBackend(ctx, &fallback)
+		if err == nil {
+			backend.Fallback = i + 1
+			backend.Data.Warnings = append(backend.Data.Warnings, fmt.Sprintf("state read from fallback %d, %s", i+1, strings.Join(failures, ", ")))
+			return backend, nil
+		}
+		if !IsUnavailableError(err) {
+			return nil, fmt.Errorf("fallback %d: %w", i+1, err)
+		}
+		failures = append(failures, fmt.Sprintf("fallback %d: %s", i+1, err))
+	}
+	return nil, fmt.Errorf("%w, %s", primaryErr, strings.Join(failures[1:], ", "))
+}
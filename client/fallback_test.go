@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBackendFallbacks(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	srv := newS3CompatServer(t, "binary/octet-stream", state)
+
+	// the primary bucket misses the state, the replica holds it
+	cfg := s3CompatConfig(srv.URL)
+	cfg.ConfigAttrs["key"] = "missing.tfstate"
+	cfg.Fallbacks = []map[string]interface{}{{"key": "prod.tfstate"}}
+	b, err := NewBackend(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, b.Fallback)
+	assert.Equal(t, "compat", b.BackendName)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+	require.NotEmpty(t, b.Data.Warnings)
+	assert.Contains(t, b.Data.Warnings[len(b.Data.Warnings)-1], "state read from fallback 1")
+
+	// a fallback of another type doesn't inherit the attributes of the primary location
+	cfg.Fallbacks = []map[string]interface{}{
+		{"key": "gone.tfstate"},
+		{"backend": "local", "path": "../examples/terraform.tfstate"},
+	}
+	b, err = NewBackend(context.Background(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 2, b.Fallback)
+	assert.Equal(t, LOCAL, b.BackendType)
+
+	// the primary is used when it holds the state
+	b, err = NewBackend(context.Background(), s3CompatConfig(srv.URL))
+	require.NoError(t, err)
+	assert.Equal(t, 0, b.Fallback)
+}
+
+func TestBackendFallbacksExhausted(t *testing.T) {
+	cfg := &BackendConfigBlock{
+		BackendName: "dr",
+		BackendType: string(LOCAL),
+		ConfigAttrs: map[string]interface{}{"path": "../examples/missing.tfstate"},
+		Fallbacks:   []map[string]interface{}{{"path": "../examples/replica.tfstate"}},
+	}
+	_, err := NewBackend(context.Background(), cfg)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorContains(t, err, "missing.tfstate")
+	assert.ErrorContains(t, err, "fallback 1")
+
+	// a state that can be read but not parsed is not retried elsewhere
+	cfg.ConfigAttrs["path"] = "../go.mod"
+	cfg.Fallbacks = []map[string]interface{}{{"path": "../examples/terraform.tfstate"}}
+	_, err = NewBackend(context.Background(), cfg)
+	assert.True(t, IsParseError(err))
+}
+
+func TestIsUnavailableError(t *testing.T) {
+	assert.True(t, IsUnavailableError(fmt.Errorf("read: %w", os.ErrNotExist)))
+	assert.True(t, IsUnavailableError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.True(t, IsUnavailableError(fmt.Errorf("get: %w", httpStatusError{503, "503 Service Unavailable"})))
+	assert.True(t, IsUnavailableError(fmt.Errorf("invoke: %w", status.Error(codes.NotFound, "no such state"))))
+	assert.False(t, IsUnavailableError(fmt.Errorf("invoke: %w", status.Error(codes.PermissionDenied, "denied"))))
+	assert.False(t, IsUnavailableError(fmt.Errorf("get: %w", httpStatusError{403, "403 Forbidden"})))
+	assert.False(t, IsUnavailableError(context.Canceled))
+	assert.False(t, IsUnavailableError(ErrSignatureMismatch))
+	assert.False(t, IsUnavailableError(nil))
+}
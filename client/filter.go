@@ -8,3 +8,9 @@ func DeleteLineageSerialFilter(meta schema.ClientMeta, parent *schema.Resource)
 	backend := client.Backend()
 	return []interface{}{"lineage", backend.Data.State.Lineage, "serial", backend.Data.State.Serial}
 }
+
+// DeleteBackendFilter will delete previous fetches of the current backend
+func DeleteBackendFilter(meta schema.ClientMeta, parent *schema.Resource) []interface{} {
+	client := meta.(*Client)
+	return []interface{}{"backend_name", client.Backend().BackendName}
+}
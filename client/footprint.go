@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// FootprintEntry counts the resources of a state per provider configuration and region
+type FootprintEntry struct {
+	ProviderSource string
+	ProviderAlias  string
+	Region         string
+	ResourceCount  int
+	InstanceCount  int
+}
+
+// ParseProviderConfig splits a provider configuration address, such as
+// module.a.provider["registry.terraform.io/hashicorp/aws"].west, into the provider source and alias
+func ParseProviderConfig(address string) (source string, alias string) {
+	start := strings.Index(address, `provider["`)
+	if start < 0 {
+		return "", ""
+	}
+	rest := address[start+len(`provider["`):]
+	end := strings.Index(rest, `"]`)
+	if end < 0 {
+		return "", ""
+	}
+	return rest[:end], strings.TrimPrefix(rest[end+len(`"]`):], ".")
+}
+
+// instanceRegion returns the region of an instance from its region attribute or its arn, empty if unknown
+func instanceRegion(instance Instance) string {
+	var attrs struct {
+		Region string `json:"region"`
+		Arn    string `json:"arn"`
+	}
+	if err := json.Unmarshal(instance.AttributesRaw, &attrs); err != nil {
+		return ""
+	}
+	if attrs.Region != "" {
+		return attrs.Region
+	}
+	if parsed, err := arn.Parse(attrs.Arn); err == nil {
+		return parsed.Region
+	}
+	return ""
+}
+
+// Footprint aggregates the resources of the state by provider source, alias and region
+func Footprint(state State) []FootprintEntry {
+	type groupKey struct{ source, alias, region string }
+	groups := make(map[groupKey]*FootprintEntry)
+	for _, resource := range state.Resources {
+		source, alias := ParseProviderConfig(resource.ProviderConfig)
+		counted := make(map[groupKey]bool)
+		for _, instance := range resource.Instances {
+			key := groupKey{source, alias, instanceRegion(instance)}
+			entry, ok := groups[key]
+			if !ok {
+				entry = &FootprintEntry{ProviderSource: key.source, ProviderAlias: key.alias, Region: key.region}
+				groups[key] = entry
+			}
+			entry.InstanceCount++
+			if !counted[key] {
+				entry.ResourceCount++
+				counted[key] = true
+			}
+		}
+	}
+
+	entries := make([]FootprintEntry, 0, len(groups))
+	for _, entry := range groups {
+		entries = append(entries, *entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.ProviderSource != b.ProviderSource {
+			return a.ProviderSource < b.ProviderSource
+		}
+		if a.ProviderAlias != b.ProviderAlias {
+			return a.ProviderAlias < b.ProviderAlias
+		}
+		return a.Region < b.Region
+	})
+	return entries
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseProviderConfig(t *testing.T) {
+	for address, expected := range map[string][2]string{
+		`provider["registry.terraform.io/hashicorp/aws"]`:                 {"registry.terraform.io/hashicorp/aws", ""},
+		`provider["registry.terraform.io/hashicorp/aws"].west`:            {"registry.terraform.io/hashicorp/aws", "west"},
+		`module.vpc.provider["registry.terraform.io/hashicorp/aws"].east`: {"registry.terraform.io/hashicorp/aws", "east"},
+		`provider.aws`: {"", ""},
+	} {
+		source, alias := ParseProviderConfig(address)
+		assert.Equal(t, expected, [2]string{source, alias}, address)
+	}
+}
+
+func TestFootprint(t *testing.T) {
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+	instance := func(attrs string) Instance { return Instance{AttributesRaw: json.RawMessage(attrs)} }
+	entries := Footprint(State{Resources: []Resource{
+		{ProviderConfig: aws, Instances: []Instance{
+			instance(`{"arn": "arn:aws:ec2:us-east-1:123456789012:subnet/subnet-1"}`),
+			instance(`{"arn": "arn:aws:ec2:us-east-1:123456789012:subnet/subnet-2"}`),
+		}},
+		{ProviderConfig: aws + ".west", Instances: []Instance{
+			instance(`{"region": "us-west-2", "arn": "arn:aws:s3:::logs"}`),
+		}},
+		{ProviderConfig: aws, Instances: []Instance{
+			instance(`{"arn": "arn:aws:iam::123456789012:role/state"}`),
+		}},
+	}})
+	assert.Equal(t, []FootprintEntry{
+		{ProviderSource: "registry.terraform.io/hashicorp/aws", Region: "", ResourceCount: 1, InstanceCount: 1},
+		{ProviderSource: "registry.terraform.io/hashicorp/aws", Region: "us-east-1", ResourceCount: 1, InstanceCount: 2},
+		{ProviderSource: "registry.terraform.io/hashicorp/aws", ProviderAlias: "west", Region: "us-west-2", ResourceCount: 1, InstanceCount: 1},
+	}, entries)
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const defaultGRPCTimeout = time.Minute
+
+// GRPCBackendConfig reads the state from a unary rpc of a state service. The rpc receives the configured state
+// name as a google.protobuf.StringValue and returns the state as a google.protobuf.BytesValue, for example:
+//
+//	rpc GetState(google.protobuf.StringValue) returns (google.protobuf.BytesValue);
+type GRPCBackendConfig struct {
+	Target string `yaml:"target"`
+	// Method is the full rpc name, for example /state.v1.StateService/GetState
+	Method string `yaml:"method"`
+	// State is sent as the request value, for example the workspace name
+	State string `yaml:"state,omitempty"`
+	// Insecure uses a plaintext connection, only meant for local development
+	Insecure bool `yaml:"insecure,omitempty"`
+	// CAFile is a PEM bundle used instead of the system roots to verify the server
+	CAFile         string        `yaml:"ca_file,omitempty"`
+	ServerName     string        `yaml:"server_name,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	TLSConfig      `yaml:",inline"`
+	BackendOptions `yaml:",inline"`
+}
+
+// Validate checks the fields of the config, the ca_file is only read when connecting
+func (c GRPCBackendConfig) Validate() error {
+	if c.Target == "" || c.Method == "" {
+		return errors.New("grpc backend requires target and method")
+	}
+	if c.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if !c.Insecure {
+		if _, err := c.tlsConfig(); err != nil {
+			return err
+		}
+	}
+	return c.BackendOptions.Validate()
+}
+
+func (c GRPCBackendConfig) transportCredentials() (credentials.TransportCredentials, error) {
+	if c.Insecure {
+		return insecure.NewCredentials(), nil
+	}
+	tlsCfg, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg.ServerName = c.ServerName
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ca_file: %w", err)
+		}
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca_file %s", c.CAFile)
+		}
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// NewGRPCTerraformBackend reads the state from a gRPC state service
+func NewGRPCTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b GRPCBackendConfig
+
+	if err := decodeBackendConfig(config, GRPC, &b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultGRPCTimeout
+	}
+
+	creds, err := b.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(callCtx, b.Target,
+		grpc.WithTransportCredentials(creds),
+		// states are commonly larger than the default 4MB message limit
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(math.MaxInt32)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %w", b.Target, err)
+	}
+	defer conn.Close()
+
+	var state wrapperspb.BytesValue
+	if err := conn.Invoke(callCtx, b.Method, wrapperspb.String(b.State), &state); err != nil {
+		return nil, fmt.Errorf("failed to get tfstate from %s%s: %w", b.Target, b.Method, err)
+	}
+
+	terraformData, err := parseAndValidate(ctx, bytes.NewReader(state.GetValue()), b.BackendOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TerraformBackend{
+		BackendType: GRPC,
+		BackendName: config.BackendName,
+		Data:        terraformData,
+	}, nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestGRPCBackend(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	srv.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "state.v1.StateService",
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "GetState",
+			Handler: func(_ interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				var in wrapperspb.StringValue
+				if err := dec(&in); err != nil {
+					return nil, err
+				}
+				if in.GetValue() != "prod" {
+					return nil, status.Error(codes.NotFound, "no such state")
+				}
+				return wrapperspb.Bytes(state), nil
+			},
+		}},
+	}, struct{}{})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	cfg := func(stateName string) *BackendConfigBlock {
+		return &BackendConfigBlock{
+			BackendName: "gateway",
+			BackendType: string(GRPC),
+			ConfigAttrs: map[string]interface{}{
+				"target":   lis.Addr().String(),
+				"method":   "/state.v1.StateService/GetState",
+				"state":    stateName,
+				"insecure": true,
+				"timeout":  "10s",
+			},
+		}
+	}
+
+	b, err := NewBackend(context.Background(), cfg("prod"))
+	require.NoError(t, err)
+	assert.Equal(t, GRPC, b.BackendType)
+	assert.Equal(t, uint64(173), b.Data.State.Serial)
+
+	_, err = NewBackend(context.Background(), cfg("staging"))
+	assert.ErrorContains(t, err, "no such state")
+
+	_, err = NewBackend(context.Background(), &BackendConfigBlock{BackendType: string(GRPC), ConfigAttrs: map[string]interface{}{"target": "localhost:1"}})
+	assert.EqualError(t, err, "grpc backend requires target and method")
+}
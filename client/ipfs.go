@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	defaultIPFSGateway = "https://ipfs.io"
+	defaultIPFSTimeout = time.Minute
+)
+
+// IPFSBackendConfig reads an immutable state snapshot by its CID from an IPFS HTTP gateway. A local node
+// can be used through its own gateway, for example http://127.0.0.1:8080
+type IPFSBackendConfig struct {
+	CID string `yaml:"cid"`
+	// Gateway is the base url of the gateway, the state is fetched from <gateway>/ipfs/<cid>
+	Gateway        string        `yaml:"gateway,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	TLSConfig      `yaml:",inline"`
+	BackendOptions `yaml:",inline"`
+}
+
+func (b IPFSBackendConfig) Validate() error {
+	if b.CID == "" {
+		return errors.New("ipfs backend requires cid")
+	}
+	if b.Gateway != "" {
+		u, err := url.Parse(b.Gateway)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid ipfs gateway %q", b.Gateway)
+		}
+	}
+	if b.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if _, err := b.tlsConfig(); err != nil {
+		return err
+	}
+	return b.BackendOptions.Validate()
+}
+
+// stateURL returns the url of the content on the gateway
+func (b IPFSBackendConfig) stateURL() string {
+	return strings.TrimSuffix(b.Gateway, "/") + "/ipfs/" + url.PathEscape(b.CID)
+}
+
+// NewIPFSTerraformBackend reads the state from an IPFS gateway, falling back to the public ipfs.io gateway
+func NewIPFSTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b IPFSBackendConfig
+
+	if err := decodeBackendConfig(config, IPFS, &b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if b.Gateway == "" {
+		b.Gateway = defaultIPFSGateway
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultIPFSTimeout
+	}
+
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	// the timeout covers reading the body, which is streamed into the parser
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.stateURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ipfs gateway %q: %w", b.Gateway, err)
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tfstate %s: %w", b.CID, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get tfstate %s from %s: %w", b.CID, b.Gateway, httpStatusError{resp.StatusCode, resp.Status})
+	}
+
+	body, html := isHTML(resp.Body)
+	if html {
+		return nil, fmt.Errorf("ipfs content %s is an HTML page, not a terraform state", b.CID)
+	}
+
+	terraformData, err := parseAndValidate(ctx, body, b.BackendOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TerraformBackend{
+		BackendType: IPFS,
+		BackendName: config.BackendName,
+		Data:        terraformData,
+	}, nil
+}
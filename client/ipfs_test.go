@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCID = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+func TestIPFSBackend(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ipfs/" + testCID:
+			_, _ = w.Write(state)
+		case "/ipfs/error-page":
+			_, _ = w.Write([]byte("<html><body>rate limited</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	b, err := NewBackend(context.Background(), &BackendConfigBlock{
+		BackendName: "snapshot",
+		BackendType: string(IPFS),
+		ConfigAttrs: map[string]interface{}{"cid": testCID, "gateway": srv.URL + "/"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, IPFS, b.BackendType)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	_, err = NewIPFSTerraformBackend(context.Background(), &BackendConfigBlock{ConfigAttrs: map[string]interface{}{"cid": "missing", "gateway": srv.URL}})
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	_, err = NewIPFSTerraformBackend(context.Background(), &BackendConfigBlock{ConfigAttrs: map[string]interface{}{"cid": "error-page", "gateway": srv.URL}})
+	assert.ErrorContains(t, err, "is an HTML page")
+
+	_, err = NewIPFSTerraformBackend(context.Background(), &BackendConfigBlock{ConfigAttrs: map[string]interface{}{"gateway": srv.URL}})
+	assert.ErrorContains(t, err, "requires cid")
+}
@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// withKMSHint explains the AccessDenied error of GetObject caused by a missing kms:Decrypt permission, which S3
+// reports like any other denied read. HeadObject doesn't decrypt the object, so it still tells whether the object
+// is encrypted with a KMS key, and which one. Other errors are returned as they are.
+func withKMSHint(ctx context.Context, svc *s3.Client, b S3BackendConfig, err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "AccessDenied" {
+		return err
+	}
+	keyID := ""
+	head, headErr := svc.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.Key),
+	})
+	switch {
+	case headErr == nil && strings.HasPrefix(string(head.ServerSideEncryption), "aws:kms"):
+		keyID = aws.ToString(head.SSEKMSKeyId)
+	case strings.Contains(apiErr.ErrorMessage(), "kms:"):
+		// some denials of the key policy name the kms action, even when the object can't be inspected
+	default:
+		return err
+	}
+	if keyID == "" {
+		return fmt.Errorf("cannot read s3 object %s/%s encrypted with KMS, the credentials need kms:Decrypt on its key: %w", b.Bucket, b.Key, err)
+	}
+	return fmt.Errorf("cannot read s3 object %s/%s encrypted with the KMS key %s, the credentials need kms:Decrypt on it: %w", b.Bucket, b.Key, keyID, err)
+}
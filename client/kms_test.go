@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3BackendKMSHint(t *testing.T) {
+	accessDenied := `<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/states/prod.tfstate":
+			if r.Method == http.MethodHead {
+				w.Header().Set("x-amz-server-side-encryption", "aws:kms")
+				w.Header().Set("x-amz-server-side-encryption-aws-kms-key-id", "arn:aws:kms:us-east-1:123456789012:key/states")
+				return
+			}
+		case "/states/plain.tfstate":
+			if r.Method == http.MethodHead {
+				return
+			}
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(accessDenied))
+	}))
+	defer srv.Close()
+
+	_, err := NewBackend(context.Background(), s3CompatConfig(srv.URL))
+	assert.ErrorContains(t, err, "encrypted with the KMS key arn:aws:kms:us-east-1:123456789012:key/states, the credentials need kms:Decrypt on it")
+	assert.ErrorContains(t, err, "AccessDenied")
+
+	// objects without KMS encryption are denied by the bucket or IAM policies
+	plain := s3CompatConfig(srv.URL)
+	plain.ConfigAttrs["key"] = "plain.tfstate"
+	_, err = NewBackend(context.Background(), plain)
+	assert.ErrorContains(t, err, "AccessDenied")
+	assert.NotContains(t, err.Error(), "kms:Decrypt")
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// globMeta are the pattern characters of filepath.Match
+const globMeta = "*?["
+
+// expandHome replaces a leading ~ of the path by the home directory of the user
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot expand %s: %w", path, err)
+	}
+	return filepath.Join(home, path[1:]), nil
+}
+
+// expandConfigs replaces the local backend configs whose path is a glob pattern by one config per matching
+// file, named <name>/<match relative to the static part of the pattern>. Other configs are kept as they are.
+func expandConfigs(configs []BackendConfigBlock) ([]BackendConfigBlock, error) {
+	expanded := make([]BackendConfigBlock, 0, len(configs))
+	for _, config := range configs {
+		path, _ := config.ConfigAttrs["path"].(string)
+		if BackendType(config.BackendType) != LOCAL || !strings.ContainsAny(path, globMeta) {
+			expanded = append(expanded, config)
+			continue
+		}
+		pattern, err := expandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path pattern of backend %q: %w", config.BackendName, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no state file matches the path %s of backend %q", path, config.BackendName)
+		}
+		sort.Strings(matches)
+		base := filepath.Dir(pattern[:strings.IndexAny(pattern, globMeta)] + "x")
+		for _, match := range matches {
+			rel, err := filepath.Rel(base, match)
+			if err != nil {
+				rel = match
+			}
+			c := config
+			c.BackendName = config.BackendName + "/" + filepath.ToSlash(rel)
+			c.ConfigAttrs = make(map[string]interface{}, len(config.ConfigAttrs))
+			for k, v := range config.ConfigAttrs {
+				c.ConfigAttrs[k] = v
+			}
+			c.ConfigAttrs["path"] = match
+			expanded = append(expanded, c)
+		}
+	}
+	return expanded, nil
+}
+
+// openWithContext opens the file in a goroutine and gives up on it when ctx is done, closing the file if the
+// open completes later
+func openWithContext(ctx context.Context, path string) (*os.File, error) {
+	type result struct {
+		f   *os.File
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		f, err := os.Open(path)
+		done <- result{f, err}
+	}()
+	select {
+	case r := <-done:
+		return r.f, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.f != nil {
+				r.f.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// contextReader bounds every read of r by ctx, like readWithContext. After ctx is done, a read blocked in the
+// kernel may still fill the buffer it was given, so the reader must not be used anymore.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	type result struct {
+		n   int
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		n, err := c.r.Read(p)
+		done <- result{n, err}
+	}()
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-c.ctx.Done():
+		return 0, c.ctx.Err()
+	}
+}
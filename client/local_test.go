@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	for path, expected := range map[string]string{
+		"~":                        home,
+		"~/a/terraform.tfstate":    filepath.Join(home, "a", "terraform.tfstate"),
+		"~other/terraform.tfstate": "~other/terraform.tfstate",
+		"./terraform.tfstate":      "./terraform.tfstate",
+	} {
+		expanded, err := expandHome(path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, expanded, path)
+	}
+}
+
+func TestExpandConfigs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	for _, project := range []string{"network", "dns"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(home, "projects", project), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(home, "projects", project, "terraform.tfstate"), []byte("{}"), 0o600))
+	}
+
+	remote := BackendConfigBlock{BackendName: "remote", BackendType: "s3", ConfigAttrs: map[string]interface{}{"bucket": "states"}}
+	configs, err := expandConfigs([]BackendConfigBlock{
+		{BackendName: "projects", BackendType: "local", Labels: map[string]string{"team": "platform"}, ConfigAttrs: map[string]interface{}{
+			"path":         "~/projects/*/terraform.tfstate",
+			"read_timeout": "10s",
+		}},
+		{BackendName: "plain", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "~/projects/dns/terraform.tfstate"}},
+		remote,
+	})
+	require.NoError(t, err)
+	require.Len(t, configs, 4)
+	assert.Equal(t, BackendConfigBlock{
+		BackendName: "projects/dns/terraform.tfstate",
+		BackendType: "local",
+		Labels:      map[string]string{"team": "platform"},
+		ConfigAttrs: map[string]interface{}{
+			"path":         filepath.Join(home, "projects", "dns", "terraform.tfstate"),
+			"read_timeout": "10s",
+		},
+	}, configs[0])
+	assert.Equal(t, "projects/network/terraform.tfstate", configs[1].BackendName)
+	assert.Equal(t, "plain", configs[2].BackendName)
+	assert.Equal(t, remote, configs[3])
+
+	_, err = expandConfigs([]BackendConfigBlock{{BackendName: "none", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "~/missing/*.tfstate"}}})
+	assert.ErrorContains(t, err, `no state file matches the path ~/missing/*.tfstate of backend "none"`)
+}
+
+func TestLocalBackendReadBufferSize(t *testing.T) {
+	backend, err := NewLocalTerraformBackend(context.Background(), &BackendConfigBlock{
+		BackendName: "efs",
+		ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate", "read_buffer_size": 4096},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", backend.Data.State.Lineage)
+
+	_, err = NewLocalTerraformBackend(context.Background(), &BackendConfigBlock{
+		ConfigAttrs: map[string]interface{}{"path": "../examples/missing.tfstate", "read_buffer_size": 4096},
+	})
+	assert.ErrorContains(t, err, "failed to read tfstate from ../examples/missing.tfstate")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+// blockingReader blocks until unblock is closed
+type blockingReader struct{ unblock chan struct{} }
+
+func (b blockingReader) Read([]byte) (int, error) {
+	<-b.unblock
+	return 0, io.EOF
+}
+
+func TestContextReader(t *testing.T) {
+	data, err := io.ReadAll(contextReader{context.Background(), strings.NewReader("state")})
+	require.NoError(t, err)
+	assert.Equal(t, "state", string(data))
+
+	unblock := make(chan struct{})
+	defer close(unblock)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = contextReader{ctx, blockingReader{unblock}}.Read(make([]byte, 8))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
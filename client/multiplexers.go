@@ -11,3 +11,15 @@ func BackendMultiplex(meta schema.ClientMeta) []schema.ClientMeta {
 	}
 	return l
 }
+
+// StateMultiplex is BackendMultiplex over the backends whose state was fetched, for the tables read from the state
+func StateMultiplex(meta schema.ClientMeta) []schema.ClientMeta {
+	var l = make([]schema.ClientMeta, 0)
+	client := meta.(*Client)
+	for name, backend := range client.Backends {
+		if backend.Data != nil {
+			l = append(l, client.withSpecificBackend(name))
+		}
+	}
+	return l
+}
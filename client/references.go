@@ -0,0 +1,154 @@
+package client
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Reference is a resource attribute of the configuration referring to another resource
+type Reference struct {
+	// FromAddress and ToAddress are configuration addresses, prefixed by the module path
+	FromAddress string
+	ToAddress   string
+	// Attribute is the path of the referring attribute, such as subnet_id or ebs_block_device[0].kms_key_id,
+	// count, for_each and depends_on are reported as attributes too
+	Attribute string
+	// Reference is the most specific traversal of the reference, such as aws_subnet.private[0].id
+	Reference string
+}
+
+// References lists the resource to resource references of the configuration, references to variables, locals
+// and module outputs are left out
+func References(cfg *Configuration) []Reference {
+	var refs []Reference
+	collectModuleReferences(&refs, "", cfg.RootModule)
+	return refs
+}
+
+func collectModuleReferences(refs *[]Reference, modulePath string, module ConfigModule) {
+	for _, resource := range module.Resources {
+		from := modulePath + resource.Address
+		seen := make(map[[2]string]bool)
+		add := func(attribute string, references []string) {
+			// terraform lists the traversal followed by its shorter prefixes, the first one is the most specific
+			for _, reference := range references {
+				to, ok := referencedResource(reference)
+				if !ok || seen[[2]string{attribute, to}] {
+					continue
+				}
+				seen[[2]string{attribute, to}] = true
+				*refs = append(*refs, Reference{
+					FromAddress: from,
+					ToAddress:   modulePath + to,
+					Attribute:   attribute,
+					Reference:   reference,
+				})
+			}
+		}
+		if resource.CountExpression != nil {
+			add("count", resource.CountExpression.References)
+		}
+		if resource.ForEachExpression != nil {
+			add("for_each", resource.ForEachExpression.References)
+		}
+		add("depends_on", resource.DependsOn)
+		walkExpressions(resource.Expressions, "", add)
+	}
+
+	names := make([]string, 0, len(module.ModuleCalls))
+	for name := range module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		collectModuleReferences(refs, modulePath+"module."+name+".", module.ModuleCalls[name].Module)
+	}
+}
+
+// walkExpressions calls add with the references of every expression of an expressions map, descending into
+// nested blocks
+func walkExpressions(expressions map[string]interface{}, prefix string, add func(attribute string, references []string)) {
+	keys := make([]string, 0, len(expressions))
+	for key := range expressions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		walkExpression(expressions[key], prefix+key, add)
+	}
+}
+
+func walkExpression(value interface{}, attribute string, add func(attribute string, references []string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if _, ok := v["constant_value"]; ok {
+			return
+		}
+		if references, ok := v["references"].([]interface{}); ok {
+			var refs []string
+			for _, r := range references {
+				if s, ok := r.(string); ok {
+					refs = append(refs, s)
+				}
+			}
+			add(attribute, refs)
+			return
+		}
+		// a single nested block
+		walkExpressions(v, attribute+".", add)
+	case []interface{}:
+		for i, block := range v {
+			walkExpression(block, attribute+"["+strconv.Itoa(i)+"]", add)
+		}
+	}
+}
+
+// referencedResource returns the configuration address of the resource a traversal refers to, such as
+// aws_subnet.private for aws_subnet.private[0].id, or false if it refers to something else
+func referencedResource(reference string) (string, bool) {
+	parts := splitTraversal(reference)
+	if len(parts) == 0 {
+		return "", false
+	}
+	switch parts[0] {
+	case "var", "local", "module", "each", "count", "path", "terraform", "self":
+		return "", false
+	case "data":
+		if len(parts) < 3 {
+			return "", false
+		}
+		return "data." + parts[1] + "." + stripIndex(parts[2]), true
+	}
+	if len(parts) < 2 {
+		return "", false
+	}
+	return parts[0] + "." + stripIndex(parts[1]), true
+}
+
+// splitTraversal splits a traversal on the dots outside of index brackets
+func splitTraversal(traversal string) []string {
+	var parts []string
+	start, depth := 0, 0
+	for i, c := range traversal {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, traversal[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, traversal[start:])
+}
+
+func stripIndex(name string) string {
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		return name[:i]
+	}
+	return name
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferences(t *testing.T) {
+	var cfg Configuration
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "root_module": {
+    "resources": [
+      {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web",
+       "expressions": {
+         "ami": {"references": ["data.aws_ami.ubuntu.id", "data.aws_ami.ubuntu"]},
+         "instance_type": {"constant_value": "t3.micro"},
+         "subnet_id": {"references": ["aws_subnet.private[0].id", "aws_subnet.private[0]", "aws_subnet.private"]},
+         "tags": {"references": ["var.tags"]},
+         "ebs_block_device": [{"kms_key_id": {"references": ["aws_kms_key.ebs.arn", "aws_kms_key.ebs"]}}]
+       },
+       "count_expression": {"references": ["var.instances"]},
+       "depends_on": ["aws_iam_role_policy.web"]}
+    ],
+    "module_calls": {
+      "network": {
+        "source": "./network",
+        "module": {
+          "resources": [
+            {"address": "aws_subnet.private", "mode": "managed", "type": "aws_subnet", "name": "private",
+             "expressions": {"vpc_id": {"references": ["aws_vpc.main.id", "aws_vpc.main"]}},
+             "for_each_expression": {"references": ["local.zones"]}}
+          ]
+        }
+      }
+    }
+  }
+}`), &cfg))
+
+	assert.Equal(t, []Reference{
+		{FromAddress: "aws_instance.web", ToAddress: "aws_iam_role_policy.web", Attribute: "depends_on", Reference: "aws_iam_role_policy.web"},
+		{FromAddress: "aws_instance.web", ToAddress: "data.aws_ami.ubuntu", Attribute: "ami", Reference: "data.aws_ami.ubuntu.id"},
+		{FromAddress: "aws_instance.web", ToAddress: "aws_kms_key.ebs", Attribute: "ebs_block_device[0].kms_key_id", Reference: "aws_kms_key.ebs.arn"},
+		{FromAddress: "aws_instance.web", ToAddress: "aws_subnet.private", Attribute: "subnet_id", Reference: "aws_subnet.private[0].id"},
+		{FromAddress: "module.network.aws_subnet.private", ToAddress: "module.network.aws_vpc.main", Attribute: "vpc_id", Reference: "aws_vpc.main.id"},
+	}, References(&cfg))
+}
+
+func TestReferencedResource(t *testing.T) {
+	for reference, expected := range map[string]string{
+		`aws_instance.web`:               "aws_instance.web",
+		`aws_instance.web["a.b"].id`:     "aws_instance.web",
+		`data.aws_ami.ubuntu.image_id`:   "data.aws_ami.ubuntu",
+		`module.network.private_subnets`: "",
+		`var.region`:                     "",
+		`each.value`:                     "",
+		`data.aws_ami`:                   "",
+	} {
+		to, ok := referencedResource(reference)
+		assert.Equal(t, expected != "", ok, reference)
+		assert.Equal(t, expected, to, reference)
+	}
+}
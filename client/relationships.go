@@ -0,0 +1,154 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Relationship relates a resource instance to the instance whose id is the value of one of its attributes, such
+// as an instance to the subnet of its subnet_id
+type Relationship struct {
+	BackendName string
+	Address     string
+	// Deposed is the deposed key of the instance, set for objects pending destruction, whose address is the one
+	// of the current object
+	Deposed      string
+	ResourceType string
+	AttributeKey string
+	Value        string
+	// Target is the instance whose id attribute is Value, of any backend
+	TargetBackendName  string
+	TargetAddress      string
+	TargetDeposed      string
+	TargetResourceType string
+	// FetchedAt is the fetch time of the least recently fetched backend of the two instances
+	FetchedAt time.Time
+}
+
+// validateRelationshipKeys checks the attribute keys of relationship_keys
+func validateRelationshipKeys(keys []string) error {
+	for _, key := range keys {
+		if key == "" {
+			return errors.New("invalid relationship_keys: empty attribute key")
+		}
+	}
+	return nil
+}
+
+// relationshipInstance is an instance with its decoded attributes
+type relationshipInstance struct {
+	backend      string
+	address      string
+	deposed      string
+	resourceType string
+	attrs        map[string]interface{}
+	fetchedAt    time.Time
+}
+
+// Relationships infers the relationships of the resource instances of all backends from the attributes of keys,
+// a string attribute or a list of strings, such as subnet_id or security_group_ids. Every value is related to the
+// instances whose id attribute has that value. Instances sharing a value are not related to each other, only to
+// its owners, which keeps the relationships linear in the number of instances.
+func Relationships(backends map[string]*TerraformBackend, keys []string) []Relationship {
+	if len(keys) == 0 {
+		return nil
+	}
+	var instances []relationshipInstance
+	owners := make(map[string][]int)
+	for name, backend := range backends {
+		if backend.Data == nil {
+			continue
+		}
+		for _, resource := range backend.Data.State.Resources {
+			for _, instance := range resource.Instances {
+				var attrs map[string]interface{}
+				if len(instance.AttributesRaw) == 0 || json.Unmarshal(instance.AttributesRaw, &attrs) != nil {
+					continue
+				}
+				if id, ok := attrs["id"].(string); ok && id != "" {
+					owners[id] = append(owners[id], len(instances))
+				}
+				instances = append(instances, relationshipInstance{
+					backend:      name,
+					address:      resource.InstanceAddress(instance),
+					deposed:      instance.Deposed,
+					resourceType: resource.Type,
+					attrs:        attrs,
+					fetchedAt:    backend.FetchedAt,
+				})
+			}
+		}
+	}
+
+	var relationships []Relationship
+	for i, source := range instances {
+		for _, key := range keys {
+			for _, value := range relationshipValues(source.attrs[key]) {
+				for _, j := range owners[value] {
+					if i == j {
+						continue
+					}
+					target := instances[j]
+					fetchedAt := source.fetchedAt
+					if target.fetchedAt.Before(fetchedAt) {
+						fetchedAt = target.fetchedAt
+					}
+					relationships = append(relationships, Relationship{
+						BackendName:        source.backend,
+						Address:            source.address,
+						Deposed:            source.deposed,
+						ResourceType:       source.resourceType,
+						AttributeKey:       key,
+						Value:              value,
+						TargetBackendName:  target.backend,
+						TargetAddress:      target.address,
+						TargetDeposed:      target.deposed,
+						TargetResourceType: target.resourceType,
+						FetchedAt:          fetchedAt,
+					})
+				}
+			}
+		}
+	}
+	sort.Slice(relationships, func(i, j int) bool {
+		a, b := relationships[i], relationships[j]
+		for _, pair := range [][2]string{
+			{a.BackendName, b.BackendName},
+			{a.Address, b.Address},
+			{a.Deposed, b.Deposed},
+			{a.AttributeKey, b.AttributeKey},
+			{a.Value, b.Value},
+			{a.TargetBackendName, b.TargetBackendName},
+			{a.TargetAddress, b.TargetAddress},
+		} {
+			if pair[0] != pair[1] {
+				return pair[0] < pair[1]
+			}
+		}
+		return a.TargetDeposed < b.TargetDeposed
+	})
+	return relationships
+}
+
+// relationshipValues returns the non empty strings of an attribute value, a string or a list of strings
+func relationshipValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		seen := make(map[string]bool, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" && !seen[s] {
+				seen[s] = true
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelationships(t *testing.T) {
+	fetched := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	instance := func(attrs string) Instance {
+		return Instance{AttributesRaw: []byte(attrs)}
+	}
+	backends := map[string]*TerraformBackend{
+		"network": {FetchedAt: fetched, Data: &TerraformData{State: State{Resources: []Resource{
+			{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []Instance{instance(`{"id": "vpc-1"}`)}},
+			{Mode: "managed", Type: "aws_subnet", Name: "private", Instances: []Instance{
+				{IndexKey: float64(0), AttributesRaw: []byte(`{"id": "subnet-1", "vpc_id": "vpc-1"}`)},
+				{IndexKey: float64(1), AttributesRaw: []byte(`{"id": "subnet-2", "vpc_id": "vpc-1"}`)},
+			}},
+			{Mode: "managed", Type: "aws_security_group", Name: "web", Instances: []Instance{instance(`{"id": "sg-1", "vpc_id": "vpc-1"}`)}},
+		}}}},
+		"apps": {FetchedAt: fetched.Add(time.Minute), Data: &TerraformData{State: State{Resources: []Resource{
+			{Mode: "managed", Type: "aws_instance", Name: "web", Instances: []Instance{
+				instance(`{"id": "i-1", "subnet_id": "subnet-2", "vpc_security_group_ids": ["sg-1", "sg-external", "sg-1"]}`),
+			}},
+			// the deposed object of a replacement is related like the current one
+			{Mode: "managed", Type: "aws_instance", Name: "api", Instances: []Instance{
+				instance(`{"id": "i-4", "subnet_id": "subnet-1"}`),
+				{Deposed: "00000001", AttributesRaw: []byte(`{"id": "i-3", "subnet_id": "subnet-1"}`)},
+			}},
+			// instances sharing a value owned by no instance are not related
+			{Mode: "managed", Type: "aws_instance", Name: "legacy", Instances: []Instance{
+				{IndexKey: float64(0), AttributesRaw: []byte(`{"id": "i-2", "subnet_id": "subnet-9"}`)},
+				{IndexKey: float64(1), AttributesRaw: []byte(`{"id": "i-5", "subnet_id": "subnet-9"}`)},
+			}},
+		}}}},
+		"checked": {},
+	}
+
+	relationships := Relationships(backends, []string{"vpc_id", "subnet_id", "vpc_security_group_ids"})
+	edges := make([]string, 0, len(relationships))
+	for _, r := range relationships {
+		edges = append(edges, fmt.Sprintf("%s/%s~%s.%s=%s -> %s/%s~%s",
+			r.BackendName, r.Address, r.Deposed, r.AttributeKey, r.Value, r.TargetBackendName, r.TargetAddress, r.TargetDeposed))
+	}
+	assert.Equal(t, []string{
+		"apps/aws_instance.api~.subnet_id=subnet-1 -> network/aws_subnet.private[0]~",
+		"apps/aws_instance.api~00000001.subnet_id=subnet-1 -> network/aws_subnet.private[0]~",
+		"apps/aws_instance.web~.subnet_id=subnet-2 -> network/aws_subnet.private[1]~",
+		"apps/aws_instance.web~.vpc_security_group_ids=sg-1 -> network/aws_security_group.web~",
+		"network/aws_security_group.web~.vpc_id=vpc-1 -> network/aws_vpc.main~",
+		"network/aws_subnet.private[0]~.vpc_id=vpc-1 -> network/aws_vpc.main~",
+		"network/aws_subnet.private[1]~.vpc_id=vpc-1 -> network/aws_vpc.main~",
+	}, edges)
+	require.Len(t, relationships, 7)
+	assert.Equal(t, Relationship{
+		BackendName:        "apps",
+		Address:            "aws_instance.web",
+		ResourceType:       "aws_instance",
+		AttributeKey:       "subnet_id",
+		Value:              "subnet-2",
+		TargetBackendName:  "network",
+		TargetAddress:      "aws_subnet.private[1]",
+		TargetResourceType: "aws_subnet",
+		FetchedAt:          fetched,
+	}, relationships[2])
+
+	assert.Empty(t, Relationships(backends, nil))
+	assert.Error(t, validateRelationshipKeys([]string{"vpc_id", ""}))
+}
+
+func TestRelationshipsSharedValue(t *testing.T) {
+	// the holders of a value are each related to its owner once, not to each other
+	subnets := make([]Instance, 5000)
+	for i := range subnets {
+		subnets[i] = Instance{IndexKey: float64(i), AttributesRaw: []byte(fmt.Sprintf(`{"id": "subnet-%d", "vpc_id": "vpc-1"}`, i))}
+	}
+	backends := map[string]*TerraformBackend{
+		"network": {Data: &TerraformData{State: State{Resources: []Resource{
+			{Mode: "managed", Type: "aws_vpc", Name: "main", Instances: []Instance{{AttributesRaw: []byte(`{"id": "vpc-1"}`)}}},
+			{Mode: "managed", Type: "aws_subnet", Name: "private", Instances: subnets},
+		}}}},
+	}
+	relationships := Relationships(backends, []string{"vpc_id"})
+	assert.Len(t, relationships, len(subnets))
+	for _, relationship := range relationships {
+		assert.Equal(t, "aws_vpc.main", relationship.TargetAddress)
+	}
+
+	// without an owner the shared value relates nothing
+	backends["network"].Data.State.Resources = backends["network"].Data.State.Resources[1:]
+	assert.Empty(t, Relationships(backends, []string{"vpc_id"}))
+}
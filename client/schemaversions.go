@@ -0,0 +1,81 @@
+package client
+
+import (
+	"sort"
+	"time"
+)
+
+// SchemaVersionsEntry is the set of schema versions a resource type is stored with across the backends
+type SchemaVersionsEntry struct {
+	ResourceType   string
+	ProviderSource string
+	SchemaVersions []int64
+	// BackendNames are the backends holding instances of the resource type
+	BackendNames  []string
+	InstanceCount int
+	// Divergent is set when the instances are stored with different schema versions, which happens when
+	// the backends were last applied with different provider versions
+	Divergent bool
+	// FetchedAt is the fetch time of the least recently fetched backend holding the resource type
+	FetchedAt time.Time
+}
+
+// SchemaVersions aggregates the schema versions of the managed resource instances of all backends by resource
+// type and provider source
+func SchemaVersions(backends map[string]*TerraformBackend) []SchemaVersionsEntry {
+	type groupKey struct{ resourceType, source string }
+	type group struct {
+		versions map[int64]bool
+		backends map[string]bool
+		count    int
+		fetched  time.Time
+	}
+	groups := make(map[groupKey]*group)
+	for name, backend := range backends {
+		if backend.Data == nil {
+			continue
+		}
+		for _, resource := range backend.Data.State.Resources {
+			if resource.Mode != "managed" || len(resource.Instances) == 0 {
+				continue
+			}
+			source, _ := ParseProviderConfig(resource.ProviderConfig)
+			key := groupKey{resource.Type, source}
+			g, ok := groups[key]
+			if !ok {
+				g = &group{versions: make(map[int64]bool), backends: make(map[string]bool)}
+				groups[key] = g
+			}
+			g.backends[name] = true
+			if g.fetched.IsZero() || backend.FetchedAt.Before(g.fetched) {
+				g.fetched = backend.FetchedAt
+			}
+			for _, instance := range resource.Instances {
+				g.versions[int64(instance.SchemaVersion)] = true
+				g.count++
+			}
+		}
+	}
+
+	entries := make([]SchemaVersionsEntry, 0, len(groups))
+	for key, g := range groups {
+		entry := SchemaVersionsEntry{ResourceType: key.resourceType, ProviderSource: key.source, InstanceCount: g.count, FetchedAt: g.fetched}
+		for version := range g.versions {
+			entry.SchemaVersions = append(entry.SchemaVersions, version)
+		}
+		sort.Slice(entry.SchemaVersions, func(i, j int) bool { return entry.SchemaVersions[i] < entry.SchemaVersions[j] })
+		for name := range g.backends {
+			entry.BackendNames = append(entry.BackendNames, name)
+		}
+		sort.Strings(entry.BackendNames)
+		entry.Divergent = len(entry.SchemaVersions) > 1
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].ResourceType != entries[j].ResourceType {
+			return entries[i].ResourceType < entries[j].ResourceType
+		}
+		return entries[i].ProviderSource < entries[j].ProviderSource
+	})
+	return entries
+}
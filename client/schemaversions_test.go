@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersions(t *testing.T) {
+	aws := `provider["registry.terraform.io/hashicorp/aws"]`
+	fetched := time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+	backend := func(fetchedAt time.Time, resources ...Resource) *TerraformBackend {
+		return &TerraformBackend{FetchedAt: fetchedAt, Data: &TerraformData{State: State{Resources: resources}}}
+	}
+	entries := SchemaVersions(map[string]*TerraformBackend{
+		"network": backend(fetched.Add(time.Second),
+			Resource{Mode: "managed", Type: "aws_vpc", ProviderConfig: aws, Instances: []Instance{{SchemaVersion: 1}}},
+			Resource{Mode: "managed", Type: "aws_instance", ProviderConfig: aws, Instances: []Instance{{SchemaVersion: 1}, {SchemaVersion: 1}}},
+			Resource{Mode: "data", Type: "aws_ami", ProviderConfig: aws, Instances: []Instance{{SchemaVersion: 0}}},
+		),
+		"apps": backend(fetched,
+			Resource{Mode: "managed", Type: "aws_instance", ProviderConfig: aws + ".west", Instances: []Instance{{SchemaVersion: 0}}},
+			Resource{Mode: "managed", Type: "aws_vpc", ProviderConfig: aws},
+		),
+		"skipped": {},
+	})
+
+	assert.Equal(t, []SchemaVersionsEntry{
+		{
+			ResourceType:   "aws_instance",
+			ProviderSource: "registry.terraform.io/hashicorp/aws",
+			SchemaVersions: []int64{0, 1},
+			BackendNames:   []string{"apps", "network"},
+			InstanceCount:  3,
+			Divergent:      true,
+			FetchedAt:      fetched,
+		},
+		{
+			ResourceType:   "aws_vpc",
+			ProviderSource: "registry.terraform.io/hashicorp/aws",
+			SchemaVersions: []int64{1},
+			BackendNames:   []string{"network"},
+			InstanceCount:  1,
+			FetchedAt:      fetched.Add(time.Second),
+		},
+	}, entries)
+}
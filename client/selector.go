@@ -0,0 +1,137 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+type selectorOperator string
+
+const (
+	selectorEquals       selectorOperator = "="
+	selectorNotEquals    selectorOperator = "!="
+	selectorIn           selectorOperator = "in"
+	selectorNotIn        selectorOperator = "notin"
+	selectorExists       selectorOperator = "exists"
+	selectorDoesNotExist selectorOperator = "!"
+)
+
+type selectorRequirement struct {
+	key      string
+	operator selectorOperator
+	values   []string
+}
+
+// LabelSelector selects backends by their labels, requirements are ANDed
+type LabelSelector []selectorRequirement
+
+// ParseLabelSelector parses a comma separated list of requirements in the syntax of Kubernetes label selectors:
+// key=value, key==value, key!=value, key in (a,b), key notin (a,b), key to require it and !key to forbid it
+func ParseLabelSelector(selector string) (LabelSelector, error) {
+	var s LabelSelector
+	for _, term := range splitSelector(selector) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label_selector %q: %w", selector, err)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// Matches reports whether the labels satisfy every requirement of the selector
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, ok := labels[r.key]
+		switch r.operator {
+		case selectorEquals, selectorIn:
+			if !ok || !contains(r.values, value) {
+				return false
+			}
+		case selectorNotEquals, selectorNotIn:
+			if ok && contains(r.values, value) {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorDoesNotExist:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// splitSelector splits the selector on the commas outside of value sets
+func splitSelector(selector string) []string {
+	var terms []string
+	start, depth := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, selector[start:])
+}
+
+func parseRequirement(term string) (selectorRequirement, error) {
+	if strings.HasPrefix(term, "!") {
+		key := strings.TrimSpace(term[1:])
+		if !validLabelKey(key) {
+			return selectorRequirement{}, fmt.Errorf("invalid key in %q", term)
+		}
+		return selectorRequirement{key: key, operator: selectorDoesNotExist}, nil
+	}
+	for _, op := range []struct {
+		token    string
+		operator selectorOperator
+	}{{"!=", selectorNotEquals}, {"==", selectorEquals}, {"=", selectorEquals}} {
+		if i := strings.Index(term, op.token); i >= 0 {
+			key, value := strings.TrimSpace(term[:i]), strings.TrimSpace(term[i+len(op.token):])
+			if !validLabelKey(key) {
+				return selectorRequirement{}, fmt.Errorf("invalid key in %q", term)
+			}
+			return selectorRequirement{key: key, operator: op.operator, values: []string{value}}, nil
+		}
+	}
+	if fields := strings.Fields(term); len(fields) >= 2 && (fields[1] == "in" || fields[1] == "notin") {
+		key, operator := fields[0], selectorOperator(fields[1])
+		set := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(term[len(fields[0]):]), fields[1]))
+		if !validLabelKey(key) || !strings.HasPrefix(set, "(") || !strings.HasSuffix(set, ")") {
+			return selectorRequirement{}, fmt.Errorf("invalid set requirement %q", term)
+		}
+		var values []string
+		for _, v := range strings.Split(set[1:len(set)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			return selectorRequirement{}, fmt.Errorf("empty set in %q", term)
+		}
+		return selectorRequirement{key: key, operator: operator, values: values}, nil
+	}
+	if !validLabelKey(term) {
+		return selectorRequirement{}, fmt.Errorf("invalid requirement %q", term)
+	}
+	return selectorRequirement{key: term, operator: selectorExists}, nil
+}
+
+func validLabelKey(key string) bool {
+	return key != "" && !strings.ContainsAny(key, " \t=!(),")
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelSelector(t *testing.T) {
+	prodPlatform := map[string]string{"team": "platform", "env": "prod"}
+	stagingData := map[string]string{"team": "data", "env": "staging", "legacy": "true"}
+
+	for selector, expected := range map[string][2]bool{
+		"":                             {true, true},
+		"team=platform,env=prod":       {true, false},
+		"team==data":                   {false, true},
+		"env!=prod":                    {false, true},
+		"env in (prod, staging)":       {true, true},
+		"env notin (staging),team":     {true, false},
+		"legacy":                       {false, true},
+		"!legacy":                      {true, false},
+		"team in (platform,data),!env": {false, false},
+		"owner!=platform":              {true, true},
+	} {
+		s, err := ParseLabelSelector(selector)
+		require.NoError(t, err, selector)
+		assert.Equal(t, expected, [2]bool{s.Matches(prodPlatform), s.Matches(stagingData)}, selector)
+	}
+
+	assert.False(t, LabelSelector{{key: "env", operator: selectorEquals, values: []string{"prod"}}}.Matches(nil))
+
+	for _, selector := range []string{"=prod", "env in prod", "env in ()", "!", "team platform"} {
+		_, err := ParseLabelSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
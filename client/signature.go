@@ -0,0 +1,77 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+var ErrSignatureMismatch = errors.New("state signature mismatch")
+
+// SignatureConfig verifies the fetched state against a sidecar object holding the hex encoded HMAC-SHA256
+// of the state bytes, as published by pipelines next to the state
+type SignatureConfig struct {
+	SignatureKey string `yaml:"signature_key,omitempty"`
+	// SignaturePath is the key of the sidecar, defaults to the state key with a .sig suffix
+	SignaturePath string `yaml:"signature_path,omitempty"`
+}
+
+func (c SignatureConfig) isSet() bool {
+	return c.SignatureKey != ""
+}
+
+func (c SignatureConfig) signaturePath(stateKey string) string {
+	if c.SignaturePath != "" {
+		return c.SignaturePath
+	}
+	return stateKey + ".sig"
+}
+
+// verifySignature checks the signature read from the sidecar against the HMAC-SHA256 of the state
+func (c SignatureConfig) verifySignature(state, signature []byte) error {
+	expected, err := hex.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return fmt.Errorf("invalid state signature: %w", err)
+	}
+	mac := hmac.New(sha256.New, []byte(c.SignatureKey))
+	mac.Write(state)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// verifyS3Signature reads the state and verifies it against the sidecar object of the bucket, the returned
+// reader must be used in place of the given one
+func verifyS3Signature(ctx context.Context, svc *s3.Client, b S3BackendConfig, state io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(state)
+	if err != nil {
+		return nil, err
+	}
+	path := b.signaturePath(b.Key)
+	result, err := svc.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get state signature %s: %w", path, err)
+	}
+	defer result.Body.Close()
+	// a hex encoded HMAC-SHA256 is 64 bytes, anything much larger isn't a signature
+	signature, err := io.ReadAll(io.LimitReader(result.Body, 1024))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read state signature %s: %w", path, err)
+	}
+	if err := b.verifySignature(data, signature); err != nil {
+		return nil, fmt.Errorf("s3 object %s/%s: %w", b.Bucket, b.Key, err)
+	}
+	return bytes.NewReader(data), nil
+}
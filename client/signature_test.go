@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3BackendSignature(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(state)
+	signature := hex.EncodeToString(mac.Sum(nil)) + "\n"
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/states/prod.tfstate":
+			_, _ = w.Write(state)
+		case "/states/prod.tfstate.sig", "/states/signatures/prod":
+			_, _ = w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	signed := func(attrs map[string]interface{}) *BackendConfigBlock {
+		cfg := s3CompatConfig(srv.URL)
+		for k, v := range attrs {
+			cfg.ConfigAttrs[k] = v
+		}
+		return cfg
+	}
+
+	b, err := NewBackend(context.Background(), signed(map[string]interface{}{"signature_key": "secret"}))
+	require.NoError(t, err)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	_, err = NewBackend(context.Background(), signed(map[string]interface{}{"signature_key": "secret", "signature_path": "signatures/prod"}))
+	require.NoError(t, err)
+
+	_, err = NewBackend(context.Background(), signed(map[string]interface{}{"signature_key": "other"}))
+	assert.ErrorIs(t, err, ErrSignatureMismatch)
+
+	_, err = NewBackend(context.Background(), signed(map[string]interface{}{"signature_key": "secret", "signature_path": "missing.sig"}))
+	assert.ErrorContains(t, err, "cannot get state signature missing.sig")
+}
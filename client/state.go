@@ -13,6 +13,12 @@ const (
 
 type TerraformData struct {
 	State State
+	// ShowJSON is set when the input was the output of `terraform show -json` instead of a raw state
+	ShowJSON *ShowJSON
+	// Warnings found while parsing the state which didn't prevent its use
+	Warnings []string
+	// RawBytes is the size of the state document as it was fetched
+	RawBytes int64
 }
 
 type State struct {
@@ -22,6 +28,9 @@ type State struct {
 	Lineage          string                 `json:"lineage"`
 	RootOutputs      map[string]OutputState `json:"outputs"`
 	Resources        []Resource             `json:"resources"`
+	CheckResults     []CheckResults         `json:"check_results,omitempty"`
+	// Extra holds top level fields unknown to the v4 format, such as fields added by OpenTofu
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
 type OutputState struct {
@@ -30,6 +39,21 @@ type OutputState struct {
 	Sensitive    bool            `json:"sensitive,omitempty"`
 }
 
+// CheckResults are the results of a check block or of custom conditions of a configuration object,
+// recorded since terraform 1.3
+type CheckResults struct {
+	ObjectKind string               `json:"object_kind"`
+	ConfigAddr string               `json:"config_addr"`
+	Status     string               `json:"status"`
+	Objects    []CheckResultsObject `json:"objects,omitempty"`
+}
+
+type CheckResultsObject struct {
+	ObjectAddr      string   `json:"object_addr"`
+	Status          string   `json:"status"`
+	FailureMessages []string `json:"failure_messages,omitempty"`
+}
+
 type Resource struct {
 	Module         string     `json:"module,omitempty"`
 	Mode           string     `json:"mode"`
@@ -56,3 +80,98 @@ type Instance struct {
 
 	CreateBeforeDestroy bool `json:"create_before_destroy,omitempty"`
 }
+
+// Machine readable output of `terraform show -json`
+// https://developer.hashicorp.com/terraform/internals/json-format
+
+type ShowJSON struct {
+	FormatVersion   string           `json:"format_version"`
+	ResourceChanges []ResourceChange `json:"resource_changes,omitempty"`
+	// Configuration is only present in the output of configuration inclusive commands, like show -json of a plan
+	Configuration *Configuration `json:"configuration,omitempty"`
+	// Values is the state of show -json of a state, PlannedValues the planned state of show -json of a plan,
+	// they are only decoded with the attribute_types option
+	Values        *StateValues `json:"values,omitempty"`
+	PlannedValues *StateValues `json:"planned_values,omitempty"`
+}
+
+type StateValues struct {
+	RootModule ValuesModule `json:"root_module"`
+}
+
+type ValuesModule struct {
+	Address      string           `json:"address,omitempty"`
+	Resources    []ValuesResource `json:"resources,omitempty"`
+	ChildModules []ValuesModule   `json:"child_modules,omitempty"`
+}
+
+type ValuesResource struct {
+	Address string `json:"address"`
+	Mode    string `json:"mode"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	// Values are the attribute values of the resource instance, unknown values of a plan are left out
+	Values map[string]interface{} `json:"values,omitempty"`
+}
+
+type Configuration struct {
+	// ProviderConfig is keyed by the provider name, optionally prefixed by its module address and suffixed by its alias
+	ProviderConfig map[string]ProviderConfig `json:"provider_config,omitempty"`
+	RootModule     ConfigModule              `json:"root_module"`
+}
+
+type ConfigModule struct {
+	Resources   []ConfigResource      `json:"resources,omitempty"`
+	ModuleCalls map[string]ModuleCall `json:"module_calls,omitempty"`
+}
+
+type ModuleCall struct {
+	Source string       `json:"source"`
+	Module ConfigModule `json:"module"`
+}
+
+type ConfigResource struct {
+	// Address is relative to the module the resource is declared in
+	Address           string `json:"address"`
+	Mode              string `json:"mode"`
+	Type              string `json:"type"`
+	Name              string `json:"name"`
+	ProviderConfigKey string `json:"provider_config_key,omitempty"`
+	// Expressions maps the attributes to their expression, nested blocks to lists of expression maps
+	Expressions       map[string]interface{} `json:"expressions,omitempty"`
+	CountExpression   *Expression            `json:"count_expression,omitempty"`
+	ForEachExpression *Expression            `json:"for_each_expression,omitempty"`
+	DependsOn         []string               `json:"depends_on,omitempty"`
+}
+
+type Expression struct {
+	ConstantValue interface{} `json:"constant_value,omitempty"`
+	References    []string    `json:"references,omitempty"`
+}
+
+type ProviderConfig struct {
+	Name              string `json:"name"`
+	FullName          string `json:"full_name,omitempty"`
+	Alias             string `json:"alias,omitempty"`
+	ModuleAddress     string `json:"module_address,omitempty"`
+	VersionConstraint string `json:"version_constraint,omitempty"`
+}
+
+type ResourceChange struct {
+	Address       string      `json:"address"`
+	ModuleAddress string      `json:"module_address,omitempty"`
+	Mode          string      `json:"mode"`
+	Type          string      `json:"type"`
+	Name          string      `json:"name"`
+	Index         interface{} `json:"index,omitempty"`
+	Change        Change      `json:"change"`
+}
+
+type Change struct {
+	Actions   []string   `json:"actions"`
+	Importing *Importing `json:"importing,omitempty"`
+}
+
+type Importing struct {
+	ID string `json:"id,omitempty"`
+}
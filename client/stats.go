@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// StateStats are sizing statistics of a parsed state
+type StateStats struct {
+	ResourceCount   int
+	InstanceCount   int
+	ResourcesByType map[string]int
+	OutputCount     int
+	// AttributeBytes is the total size of the instance attributes as stored in the state
+	AttributeBytes int
+	// MaxModuleDepth is the deepest module nesting, 0 when all resources are in the root module
+	MaxModuleDepth int
+	// SensitiveCount is the number of sensitive outputs and sensitive instance attribute paths
+	SensitiveCount int
+}
+
+// NewStateStats computes the statistics of the parsed state
+func NewStateStats(data *TerraformData) StateStats {
+	stats := StateStats{
+		ResourceCount:   len(data.State.Resources),
+		ResourcesByType: make(map[string]int),
+		OutputCount:     len(data.State.RootOutputs),
+	}
+	for _, output := range data.State.RootOutputs {
+		if output.Sensitive {
+			stats.SensitiveCount++
+		}
+	}
+	for _, resource := range data.State.Resources {
+		stats.ResourcesByType[resource.Type]++
+		if depth := moduleDepth(resource.Module); depth > stats.MaxModuleDepth {
+			stats.MaxModuleDepth = depth
+		}
+		for _, instance := range resource.Instances {
+			stats.InstanceCount++
+			stats.AttributeBytes += len(instance.AttributesRaw)
+			for k, v := range instance.AttributesFlat {
+				stats.AttributeBytes += len(k) + len(v)
+			}
+			var paths []json.RawMessage
+			if err := json.Unmarshal(instance.AttributeSensitivePaths, &paths); err == nil {
+				stats.SensitiveCount += len(paths)
+			}
+		}
+	}
+	return stats
+}
+
+// DryParse fetches and parses the state of the backend and returns its statistics, without emitting any table
+func DryParse(ctx context.Context, cfg *BackendConfigBlock) (*StateStats, error) {
+	backend, err := NewBackend(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	stats := NewStateStats(backend.Data)
+	return &stats, nil
+}
+
+// moduleDepth counts the module calls of a module address such as module.a["x"].module.b
+func moduleDepth(address string) int {
+	depth, start, inIndex := 0, 0, false
+	for i := 0; i <= len(address); i++ {
+		if i < len(address) {
+			switch c := address[i]; {
+			case c == '[':
+				inIndex = true
+				continue
+			case c == ']':
+				inIndex = false
+				continue
+			case inIndex || c != '.':
+				continue
+			}
+		}
+		if address[start:i] == "module" {
+			depth++
+		}
+		start = i + 1
+	}
+	return depth
+}
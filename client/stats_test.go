@@ -0,0 +1,34 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDryParse(t *testing.T) {
+	stats, err := DryParse(context.Background(), &BackendConfigBlock{
+		BackendName: "mylocal",
+		BackendType: "local",
+		ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10, stats.ResourceCount)
+	assert.Equal(t, 2, stats.OutputCount)
+	assert.Equal(t, 1, stats.ResourcesByType["aws_subnet"])
+	assert.Equal(t, 2, stats.MaxModuleDepth)
+	assert.Positive(t, stats.AttributeBytes)
+}
+
+func TestModuleDepth(t *testing.T) {
+	for address, depth := range map[string]int{
+		"":                                    0,
+		"module.logs":                         1,
+		"module.webapp.module.ecs_task_roles": 2,
+		`module.a["module.x"].module.b[0]`:    2,
+	} {
+		assert.Equal(t, depth, moduleDepth(address), address)
+	}
+}
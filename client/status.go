@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BackendStatus tells whether the state of a backend exists and can be read, it is checked without fetching
+// the state when the backend type supports it
+type BackendStatus struct {
+	// Reachable is false when the storage of the state could not be reached, on network or server errors
+	Reachable bool
+	Exists    bool
+	// Size is the size of the state in bytes, 0 when it is unknown
+	Size         int64
+	LastModified *time.Time
+	Error        string
+}
+
+// BackendStatusFunc checks the state of a backend config without fetching it. The missing state or the failed
+// request is returned as an error, like the factory of the backend does.
+type BackendStatusFunc func(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error)
+
+// backendStatusFuncs are the status checks of the backend types which can stat, HEAD or HeadObject their state,
+// the state of other backend types is fetched to check it
+var backendStatusFuncs = map[BackendType]BackendStatusFunc{
+	LOCAL:    localStatus,
+	S3:       s3Status,
+	R2:       r2Status,
+	SCALEWAY: scalewayStatus,
+	IPFS:     ipfsStatus,
+	AZURERM:  azureRMStatus,
+	WEBDAV:   webDAVStatus,
+	MONGODB:  mongoStatus,
+}
+
+// RegisterBackendStatus adds or replaces the status check of a backend type, it must be called after the backend
+// type is registered, as RegisterBackend drops the status check of the type it replaces
+func RegisterBackendStatus(backendType BackendType, check BackendStatusFunc) {
+	backendStatusFuncs[backendType] = check
+}
+
+// CheckBackendStatus checks the state at the primary location of the backend config and returns the backend with
+// its Status set, and no Data. Failures to reach or read the state are reported by the status, the error is only
+// set for an invalid config.
+func CheckBackendStatus(ctx context.Context, cfg *BackendConfigBlock) (*TerraformBackend, error) {
+	if err := ValidateConfig(cfg); err != nil {
+		return nil, err
+	}
+	var backendStatus *BackendStatus
+	var err error
+	if check, ok := backendStatusFuncs[BackendType(cfg.BackendType)]; ok {
+		backendStatus, err = check(ctx, cfg)
+		if err == nil {
+			backendStatus.Reachable, backendStatus.Exists = true, true
+		}
+	} else {
+		// without a cheaper check the state is fetched, and the result of the fetch is its status
+		var backend *TerraformBackend
+		if backend, err = newBackend(ctx, cfg); err == nil {
+			backendStatus = fetchedStatus(backend)
+		}
+	}
+	if err != nil {
+		backendStatus = &BackendStatus{
+			Reachable: !IsUnavailableError(err) || isNotFoundError(err),
+			Error:     err.Error(),
+		}
+	}
+	return &TerraformBackend{
+		BackendType: BackendType(cfg.BackendType),
+		BackendName: cfg.BackendName,
+		Labels:      cfg.Labels,
+		FetchedAt:   time.Now().UTC(),
+		Status:      backendStatus,
+	}, nil
+}
+
+// fetchedStatus is the status of a backend whose state was fetched
+func fetchedStatus(backend *TerraformBackend) *BackendStatus {
+	s := &BackendStatus{Reachable: true, Exists: true}
+	if backend.Data != nil {
+		s.Size = backend.Data.RawBytes
+	}
+	return s
+}
+
+// isNotFoundError reports whether err was caused by a missing state, of a storage which could be reached
+func isNotFoundError(err error) bool {
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, gridfs.ErrFileNotFound) {
+		return true
+	}
+	var noSuchKey *types.NoSuchKey
+	var noSuchBucket *types.NoSuchBucket
+	var bucketNotFound manager.BucketNotFound
+	if errors.As(err, &noSuchKey) || errors.As(err, &noSuchBucket) || errors.As(err, &bucketNotFound) {
+		return true
+	}
+	var responseErr *awshttp.ResponseError
+	if errors.As(err, &responseErr) {
+		return responseErr.HTTPStatusCode() == http.StatusNotFound
+	}
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusNotFound
+	}
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	return errors.As(err, &grpcErr) && grpcErr.GRPCStatus().Code() == codes.NotFound
+}
+
+func localStatus(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var b LocalBackendConfig
+	if err := decodeBackendConfig(config, LOCAL, &b); err != nil {
+		return nil, err
+	}
+	path, err := expandHome(b.Path)
+	if err != nil {
+		return nil, err
+	}
+	if b.ReadTimeout == 0 {
+		b.ReadTimeout = defaultLocalReadTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.ReadTimeout)
+	defer cancel()
+	var info os.FileInfo
+	// info is only read once the stat returned
+	if _, err := readWithContext(ctx, func() ([]byte, error) {
+		var err error
+		info, err = os.Stat(path)
+		return nil, err
+	}); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("timed out reading tfstate from %s after %s: %w", path, b.ReadTimeout, err)
+		}
+		return nil, fmt.Errorf("failed to read tfstate from %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory, not a terraform state", path)
+	}
+	lastModified := info.ModTime().UTC()
+	return &BackendStatus{Size: info.Size(), LastModified: &lastModified}, nil
+}
+
+func s3Status(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var b S3BackendConfig
+	if err := decodeBackendConfig(config, S3, &b); err != nil {
+		return nil, err
+	}
+	return headObjectStatus(ctx, b)
+}
+
+func r2Status(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var r R2BackendConfig
+	if err := decodeBackendConfig(config, R2, &r); err != nil {
+		return nil, err
+	}
+	return headObjectStatus(ctx, r.S3Config())
+}
+
+func scalewayStatus(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var c ScalewayBackendConfig
+	if err := decodeBackendConfig(config, SCALEWAY, &c); err != nil {
+		return nil, err
+	}
+	return headObjectStatus(ctx, c.S3Config())
+}
+
+// headObjectStatus checks the state object of an s3 compatible bucket with HeadObject
+func headObjectStatus(ctx context.Context, b S3BackendConfig) (*BackendStatus, error) {
+	svc, err := newS3Client(ctx, &b)
+	if err != nil {
+		return nil, err
+	}
+	result, err := svc.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(b.Bucket),
+		Key:    aws.String(b.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tfstate %s/%s: %w", b.Bucket, b.Key, err)
+	}
+	return &BackendStatus{Size: result.ContentLength, LastModified: result.LastModified}, nil
+}
+
+func ipfsStatus(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var b IPFSBackendConfig
+	if err := decodeBackendConfig(config, IPFS, &b); err != nil {
+		return nil, err
+	}
+	if b.Gateway == "" {
+		b.Gateway = defaultIPFSGateway
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultIPFSTimeout
+	}
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, b.stateURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ipfs gateway %q: %w", b.Gateway, err)
+	}
+	s, _, err := headStatus(httpClient, req, b.CID+" from "+b.Gateway)
+	return s, err
+}
+
+func azureRMStatus(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var b AzureRMBackendConfig
+	if err := decodeBackendConfig(config, AZURERM, &b); err != nil {
+		return nil, err
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultAzureTimeout
+	}
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	req, err := b.newRequest(ctx, http.MethodHead)
+	if err != nil {
+		return nil, err
+	}
+	s, header, err := headStatus(httpClient, req, b.location())
+	if err != nil {
+		return nil, err
+	}
+	if header.Get("x-ms-resource-type") == "directory" {
+		return nil, fmt.Errorf("%s is a directory, not a terraform state", b.location())
+	}
+	return s, nil
+}
+
+func webDAVStatus(ctx context.Context, config *BackendConfigBlock) (*BackendStatus, error) {
+	var b WebDAVBackendConfig
+	if err := decodeBackendConfig(config, WEBDAV, &b); err != nil {
+		return nil, err
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultWebDAVTimeout
+	}
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	req, err := b.newRequest(ctx, http.MethodHead)
+	if err != nil {
+		return nil, err
+	}
+	s, _, err := headStatus(httpClient, req, b.stateURL())
+	return s, err
+}
+
+// headStatus sends the HEAD request of a state and returns its status and the response headers, loc describes
+// the state for error messages. A nil client is http.DefaultClient.
+func headStatus(httpClient *http.Client, req *http.Request, loc string) (*BackendStatus, http.Header, error) {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get tfstate %s: %w", loc, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("failed to get tfstate %s: %w", loc, httpStatusError{resp.StatusCode, resp.Status})
+	}
+	s := &BackendStatus{}
+	if resp.ContentLength > 0 {
+		s.Size = resp.ContentLength
+	}
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		lastModified = lastModified.UTC()
+		s.LastModified = &lastModified
+	}
+	return s, resp.Header, nil
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckBackendStatus(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	info, err := os.Stat("../examples/terraform.tfstate")
+	require.NoError(t, err)
+
+	local := func(path string) *BackendConfigBlock {
+		return &BackendConfigBlock{BackendName: "local", BackendType: string(LOCAL), ConfigAttrs: map[string]interface{}{"path": path}}
+	}
+	b, err := CheckBackendStatus(context.Background(), local("../examples/terraform.tfstate"))
+	require.NoError(t, err)
+	assert.Nil(t, b.Data)
+	assert.Equal(t, &BackendStatus{Reachable: true, Exists: true, Size: info.Size(), LastModified: timePtr(info.ModTime().UTC())}, b.Status)
+
+	b, err = CheckBackendStatus(context.Background(), local("../examples/missing.tfstate"))
+	require.NoError(t, err)
+	assert.True(t, b.Status.Reachable)
+	assert.False(t, b.Status.Exists)
+	assert.Contains(t, b.Status.Error, "missing.tfstate")
+
+	b, err = CheckBackendStatus(context.Background(), local("../examples"))
+	require.NoError(t, err)
+	assert.False(t, b.Status.Exists)
+	assert.Contains(t, b.Status.Error, "is a directory")
+
+	srv := newS3CompatServer(t, "application/json", state)
+	b, err = CheckBackendStatus(context.Background(), s3CompatConfig(srv.URL))
+	require.NoError(t, err)
+	assert.Equal(t, S3, b.BackendType)
+	assert.True(t, b.Status.Exists)
+	assert.Equal(t, int64(len(state)), b.Status.Size)
+
+	missing := s3CompatConfig(srv.URL)
+	missing.ConfigAttrs["key"] = "staging.tfstate"
+	b, err = CheckBackendStatus(context.Background(), missing)
+	require.NoError(t, err)
+	assert.True(t, b.Status.Reachable)
+	assert.False(t, b.Status.Exists)
+	assert.Contains(t, b.Status.Error, "states/staging.tfstate")
+
+	_, err = CheckBackendStatus(context.Background(), local(""))
+	assert.ErrorContains(t, err, "local backend requires path")
+}
+
+func TestCheckBackendStatusHTTP(t *testing.T) {
+	lastModified := time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		switch r.URL.Path {
+		case "/dav/prod.tfstate":
+			w.Header().Set("Content-Length", "1234")
+			w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+		case "/dav/broken.tfstate":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	webdav := func(path string) *BackendConfigBlock {
+		return &BackendConfigBlock{BackendName: "dav", BackendType: string(WEBDAV), ConfigAttrs: map[string]interface{}{
+			"url": srv.URL + "/dav", "path": path,
+		}}
+	}
+	b, err := CheckBackendStatus(context.Background(), webdav("prod.tfstate"))
+	require.NoError(t, err)
+	assert.Equal(t, &BackendStatus{Reachable: true, Exists: true, Size: 1234, LastModified: &lastModified}, b.Status)
+
+	b, err = CheckBackendStatus(context.Background(), webdav("missing.tfstate"))
+	require.NoError(t, err)
+	assert.True(t, b.Status.Reachable)
+	assert.False(t, b.Status.Exists)
+	assert.Contains(t, b.Status.Error, "404 Not Found")
+
+	b, err = CheckBackendStatus(context.Background(), webdav("broken.tfstate"))
+	require.NoError(t, err)
+	assert.False(t, b.Status.Reachable)
+	assert.Contains(t, b.Status.Error, "502 Bad Gateway")
+}
+
+func TestCheckBackendStatusFetch(t *testing.T) {
+	RegisterBackend("static", func(_ context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+		if config.BackendName == "down" {
+			return nil, errors.New("connection refused")
+		}
+		return &TerraformBackend{BackendType: "static", BackendName: config.BackendName, Data: &TerraformData{RawBytes: 42}}, nil
+	})
+	defer delete(backendFactories, "static")
+
+	// backend types without a status check are fetched
+	b, err := CheckBackendStatus(context.Background(), &BackendConfigBlock{BackendName: "up", BackendType: "static"})
+	require.NoError(t, err)
+	assert.Nil(t, b.Data)
+	assert.Equal(t, &BackendStatus{Reachable: true, Exists: true, Size: 42}, b.Status)
+
+	b, err = CheckBackendStatus(context.Background(), &BackendConfigBlock{BackendName: "down", BackendType: "static"})
+	require.NoError(t, err)
+	assert.Equal(t, "connection refused", b.Status.Error)
+}
+
+func TestConfigureStatusOnly(t *testing.T) {
+	meta, diags := Configure(hclog.NewNullLogger(), &Config{
+		StatusOnly: true,
+		Config: []BackendConfigBlock{
+			{BackendName: "present", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "../examples/terraform.tfstate"}},
+			{BackendName: "missing", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "../examples/missing.tfstate"}},
+		},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	c := meta.(*Client)
+	require.Len(t, c.Backends, 2)
+	assert.True(t, c.Backends["present"].Status.Exists)
+	assert.False(t, c.Backends["missing"].Status.Exists)
+	assert.Len(t, BackendMultiplex(c), 2)
+	assert.Empty(t, StateMultiplex(c))
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig is the outbound TLS policy of backends fetching the state over the network
+type TLSConfig struct {
+	TLSMinVersion   string   `yaml:"tls_min_version,omitempty"`
+	TLSCipherSuites []string `yaml:"tls_cipher_suites,omitempty"`
+}
+
+func (c TLSConfig) isSet() bool {
+	return c.TLSMinVersion != "" || len(c.TLSCipherSuites) > 0
+}
+
+// tlsConfig builds the tls config, cipher suites are names as listed by tls.CipherSuites
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSMinVersion != "" {
+		v, ok := tlsVersions[c.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls_min_version %q", c.TLSMinVersion)
+		}
+		cfg.MinVersion = v
+	}
+	if len(c.TLSCipherSuites) > 0 {
+		suites := make(map[string]uint16)
+		for _, s := range tls.CipherSuites() {
+			suites[s.Name] = s.ID
+		}
+		for _, name := range c.TLSCipherSuites {
+			id, ok := suites[name]
+			if !ok {
+				return nil, fmt.Errorf("unsupported tls cipher suite %q", name)
+			}
+			cfg.CipherSuites = append(cfg.CipherSuites, id)
+		}
+	}
+	return cfg, nil
+}
+
+// httpClient returns an http client applying the TLS policy, or nil when no policy was configured
+func (c TLSConfig) httpClient() (*http.Client, error) {
+	if !c.isSet() {
+		return nil, nil
+	}
+	tlsCfg, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsCfg
+	return &http.Client{Transport: transport}, nil
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSConfig(t *testing.T) {
+	client, err := TLSConfig{}.httpClient()
+	require.NoError(t, err)
+	assert.Nil(t, client)
+
+	cfg, err := TLSConfig{
+		TLSMinVersion:   "1.3",
+		TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+	}.tlsConfig()
+	require.NoError(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+	assert.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, cfg.CipherSuites)
+
+	_, err = TLSConfig{TLSMinVersion: "1.4"}.tlsConfig()
+	assert.EqualError(t, err, `unsupported tls_min_version "1.4"`)
+
+	_, err = TLSConfig{TLSCipherSuites: []string{"TLS_RSA_WITH_RC4_128_SHA"}}.tlsConfig()
+	assert.EqualError(t, err, `unsupported tls cipher suite "TLS_RSA_WITH_RC4_128_SHA"`)
+}
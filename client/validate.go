@@ -0,0 +1,75 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// backendConfig is the decoded config of a backend type
+type backendConfig interface {
+	Validate() error
+}
+
+// backendConfigs creates the config of the built-in backend types, for ValidateConfig
+var backendConfigs = map[BackendType]func() backendConfig{
+	LOCAL:    func() backendConfig { return &LocalBackendConfig{} },
+	S3:       func() backendConfig { return &S3BackendConfig{} },
+	R2:       func() backendConfig { return &R2BackendConfig{} },
+	SCALEWAY: func() backendConfig { return &ScalewayBackendConfig{} },
+	GRPC:     func() backendConfig { return &GRPCBackendConfig{} },
+	IPFS:     func() backendConfig { return &IPFSBackendConfig{} },
+	AZURERM:  func() backendConfig { return &AzureRMBackendConfig{} },
+	WEBDAV:   func() backendConfig { return &WebDAVBackendConfig{} },
+	EXEC:     func() backendConfig { return &ExecBackendConfig{} },
+	MONGODB:  func() backendConfig { return &MongoBackendConfig{} },
+}
+
+// decodeBackendConfig decodes the backend attributes of the config block, as read from the HCL or YAML
+// provider config, into out
+func decodeBackendConfig(config *BackendConfigBlock, backendType BackendType, out interface{}) error {
+	cfgBytes, _ := yaml.Marshal(config.ConfigAttrs)
+	if err := yaml.Unmarshal(cfgBytes, out); err != nil {
+		return fmt.Errorf("cannot parse %s backend config: %w", backendType, err)
+	}
+	return nil
+}
+
+// ValidateConfig decodes the config block and validates its fields like the backend factory does, without
+// reading the state, so configs can be linted before they reach a runner. Backends added by RegisterBackend
+// are only checked to be registered.
+func ValidateConfig(cfg *BackendConfigBlock) error {
+	if err := validateLocation(cfg); err != nil {
+		return err
+	}
+	for i := range cfg.Fallbacks {
+		fallback := cfg.fallbackConfig(i)
+		if err := validateLocation(&fallback); err != nil {
+			return fmt.Errorf("fallback %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validateLocation(cfg *BackendConfigBlock) error {
+	if cfg.BackendName == "" {
+		return errors.New("backend requires name")
+	}
+	backendType := BackendType(cfg.BackendType)
+	if _, ok := backendFactories[backendType]; !ok {
+		return fmt.Errorf("unsupported backend %q", cfg.BackendType)
+	}
+	newConfig, ok := backendConfigs[backendType]
+	if !ok {
+		return nil
+	}
+	c := newConfig()
+	if err := decodeBackendConfig(cfg, backendType, c); err != nil {
+		return err
+	}
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("backend %q: %w", cfg.BackendName, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config BackendConfigBlock
+		err    string
+	}{
+		{"local", BackendConfigBlock{BackendName: "l", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "/does/not/exist.tfstate"}}, ""},
+		{"local without path", BackendConfigBlock{BackendName: "l", BackendType: "local"}, "local backend requires path"},
+		{"negative buffer", BackendConfigBlock{BackendName: "l", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "a", "read_buffer_size": -1}}, "read_buffer_size must not be negative"},
+		{"bad min version", BackendConfigBlock{BackendName: "l", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "a", "min_terraform_version": "latest"}}, "invalid min_terraform_version"},
+		{"undecodable", BackendConfigBlock{BackendName: "l", BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "a", "read_buffer_size": "big"}}, "cannot parse local backend config"},
+		{"s3", BackendConfigBlock{BackendName: "s", BackendType: "s3", ConfigAttrs: map[string]interface{}{"bucket": "tf-states", "key": "terraform.tfstate", "region": "eu-west-1"}}, ""},
+		{"s3 without key", BackendConfigBlock{BackendName: "s", BackendType: "s3", ConfigAttrs: map[string]interface{}{"bucket": "tf-states"}}, "one of key and key_template is required"},
+		{"s3 key and template", BackendConfigBlock{BackendName: "s", BackendType: "s3", ConfigAttrs: map[string]interface{}{"bucket": "b", "key": "k", "key_template": "${region}/k"}}, "only one of key and key_template can be set"},
+		{"s3 partition mismatch", BackendConfigBlock{BackendName: "s", BackendType: "s3", ConfigAttrs: map[string]interface{}{"bucket": "b", "key": "k", "region": "cn-north-1", "role_arn": "arn:aws:iam::123456789012:role/state"}}, "does not match"},
+		{"s3 tls", BackendConfigBlock{BackendName: "s", BackendType: "s3", ConfigAttrs: map[string]interface{}{"bucket": "b", "key": "k", "tls_min_version": "1.9"}}, "unsupported tls_min_version"},
+		{"r2 without account", BackendConfigBlock{BackendName: "r", BackendType: "r2", ConfigAttrs: map[string]interface{}{"bucket": "b", "key": "k"}}, "r2 backend requires account_id"},
+		{"grpc without method", BackendConfigBlock{BackendName: "g", BackendType: "grpc", ConfigAttrs: map[string]interface{}{"target": "localhost:50051"}}, "grpc backend requires target and method"},
+		{"ipfs gateway", BackendConfigBlock{BackendName: "i", BackendType: "ipfs", ConfigAttrs: map[string]interface{}{"cid": "bafy", "gateway": "ipfs.io"}}, "invalid ipfs gateway"},
+		{"unsupported", BackendConfigBlock{BackendName: "x", BackendType: "consul"}, `unsupported backend "consul"`},
+		{"without name", BackendConfigBlock{BackendType: "local", ConfigAttrs: map[string]interface{}{"path": "a"}}, "backend requires name"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateConfig(&tc.config)
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestValidateConfigRegisteredBackend(t *testing.T) {
+	RegisterBackend("validated", func(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+		return nil, nil
+	})
+	defer delete(backendFactories, "validated")
+
+	assert.NoError(t, ValidateConfig(&BackendConfigBlock{BackendName: "v", BackendType: "validated"}))
+}
@@ -0,0 +1,58 @@
+package client
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// terraformVersion is a major.minor.patch version, pre-release and build metadata are ignored
+type terraformVersion [3]int
+
+func parseTerraformVersion(v string) (terraformVersion, error) {
+	var version terraformVersion
+	core := strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+	parts := strings.Split(core, ".")
+	if core == "" || len(parts) > 3 {
+		return version, fmt.Errorf("invalid terraform version %q", v)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return version, fmt.Errorf("invalid terraform version %q", v)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
+
+func (v terraformVersion) less(other terraformVersion) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] < other[i]
+		}
+	}
+	return false
+}
+
+// checkMinTerraformVersion returns an error if the terraform version that wrote the state is older than minVersion
+func checkMinTerraformVersion(stateVersion string, minVersion string) error {
+	minimum, err := parseTerraformVersion(minVersion)
+	if err != nil {
+		return fmt.Errorf("invalid min_terraform_version: %w", err)
+	}
+	if stateVersion == "" {
+		return fmt.Errorf("%w: state has no terraform_version, minimum is %s", ErrTerraformVersionTooOld, minVersion)
+	}
+	v, err := parseTerraformVersion(stateVersion)
+	if err != nil {
+		return err
+	}
+	if v.less(minimum) {
+		return fmt.Errorf("%w: state was written by terraform %s, minimum is %s", ErrTerraformVersionTooOld, stateVersion, minVersion)
+	}
+	return nil
+}
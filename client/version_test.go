@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMinTerraformVersion(t *testing.T) {
+	assert.NoError(t, checkMinTerraformVersion("1.5.7", "1.5.0"))
+	assert.NoError(t, checkMinTerraformVersion("1.5.0", "1.5"))
+	assert.NoError(t, checkMinTerraformVersion("1.10.0", "1.9.5"))
+	assert.NoError(t, checkMinTerraformVersion("1.6.0-beta1", "1.6.0"))
+
+	err := checkMinTerraformVersion("0.12.16", "1.0.0")
+	assert.ErrorIs(t, err, ErrTerraformVersionTooOld)
+	assert.EqualError(t, err, "terraform version is below the minimum: state was written by terraform 0.12.16, minimum is 1.0.0")
+	assert.ErrorIs(t, checkMinTerraformVersion("", "1.0.0"), ErrTerraformVersionTooOld)
+
+	assert.EqualError(t, checkMinTerraformVersion("1.5.7", "latest"), `invalid min_terraform_version: invalid terraform version "latest"`)
+}
+
+func TestParseMinTerraformVersion(t *testing.T) {
+	state := `{"version": 4, "terraform_version": "0.12.16"}`
+
+	data, err := parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{MinTerraformVersion: "1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"terraform version is below the minimum: state was written by terraform 0.12.16, minimum is 1.0.0"}, data.Warnings)
+
+	_, err = parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{MinTerraformVersion: "1.0.0", EnforceMinTerraformVersion: true})
+	assert.ErrorIs(t, err, ErrTerraformVersionTooOld)
+
+	data, err = parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{MinTerraformVersion: "0.12"})
+	require.NoError(t, err)
+	assert.Empty(t, data.Warnings)
+
+	// an unparsable terraform_version only warns, unless the minimum is enforced
+	state = `{"version": 4, "terraform_version": "custom-build"}`
+	data, err = parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{MinTerraformVersion: "1.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{`invalid terraform version "custom-build"`}, data.Warnings)
+
+	_, err = parseAndValidate(context.Background(), strings.NewReader(state), BackendOptions{MinTerraformVersion: "1.0.0", EnforceMinTerraformVersion: true})
+	assert.EqualError(t, err, `invalid terraform version "custom-build"`)
+}
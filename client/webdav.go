@@ -0,0 +1,126 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultWebDAVTimeout = time.Minute
+
+// WebDAVBackendConfig reads the state file from a WebDAV server with a plain GET, without PROPFIND, so it
+// works with servers which only allow reading files
+type WebDAVBackendConfig struct {
+	// URL is the base url of the WebDAV share, such as https://files.example.com/remote.php/dav/files/infra
+	URL string `yaml:"url"`
+	// Path is the path of the state file within the share
+	Path string `yaml:"path"`
+	// Username and Password are sent with basic authentication, they fall back to WEBDAV_USERNAME and
+	// WEBDAV_PASSWORD
+	Username       string        `yaml:"username,omitempty"`
+	Password       string        `yaml:"password,omitempty"`
+	Timeout        time.Duration `yaml:"timeout,omitempty"`
+	TLSConfig      `yaml:",inline"`
+	BackendOptions `yaml:",inline"`
+}
+
+func (b WebDAVBackendConfig) Validate() error {
+	if b.URL == "" || b.Path == "" {
+		return errors.New("webdav backend requires url and path")
+	}
+	u, err := url.Parse(b.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webdav url %q", b.URL)
+	}
+	if strings.HasSuffix(b.Path, "/") {
+		return fmt.Errorf("webdav path %q is a collection, not a state file", b.Path)
+	}
+	if b.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if _, err := b.tlsConfig(); err != nil {
+		return err
+	}
+	return b.BackendOptions.Validate()
+}
+
+// stateURL joins the path to the url of the share, escaping its segments
+func (b WebDAVBackendConfig) stateURL() string {
+	segments := strings.Split(strings.TrimPrefix(b.Path, "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.TrimSuffix(b.URL, "/") + "/" + strings.Join(segments, "/")
+}
+
+// newRequest creates a request of the state file, with basic authentication when a username is set
+func (b WebDAVBackendConfig) newRequest(ctx context.Context, method string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, b.stateURL(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webdav url %q: %w", b.URL, err)
+	}
+	if username := envFallback(b.Username, "WEBDAV_USERNAME"); username != "" {
+		req.SetBasicAuth(username, envFallback(b.Password, "WEBDAV_PASSWORD"))
+	}
+	return req, nil
+}
+
+// NewWebDAVTerraformBackend reads the state from a WebDAV share
+func NewWebDAVTerraformBackend(ctx context.Context, config *BackendConfigBlock) (*TerraformBackend, error) {
+	var b WebDAVBackendConfig
+	if err := decodeBackendConfig(config, WEBDAV, &b); err != nil {
+		return nil, err
+	}
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	if b.Timeout == 0 {
+		b.Timeout = defaultWebDAVTimeout
+	}
+
+	httpClient, err := b.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	// the timeout covers reading the body, which is streamed into the parser
+	ctx, cancel := context.WithTimeout(ctx, b.Timeout)
+	defer cancel()
+	stateURL := b.stateURL()
+	req, err := b.newRequest(ctx, http.MethodGet)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tfstate %s: %w", stateURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get tfstate %s: %w", stateURL, httpStatusError{resp.StatusCode, resp.Status})
+	}
+
+	// a GET of a collection returns its listing on most servers
+	body, html := isHTML(resp.Body)
+	if html {
+		return nil, fmt.Errorf("webdav resource %s is an HTML page, not a terraform state", stateURL)
+	}
+
+	terraformData, err := parseAndValidate(ctx, body, b.BackendOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TerraformBackend{
+		BackendType: WEBDAV,
+		BackendName: config.BackendName,
+		Data:        terraformData,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebDAVBackend(t *testing.T) {
+	state, err := os.ReadFile("../examples/terraform.tfstate")
+	require.NoError(t, err)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "infra" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.EscapedPath() {
+		case "/dav/team%20a/terraform.tfstate":
+			_, _ = w.Write(state)
+		case "/dav/listing":
+			_, _ = w.Write([]byte("<!DOCTYPE html><html><body>Index of /dav/listing</body></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	config := func(path string, password string) *BackendConfigBlock {
+		return &BackendConfigBlock{
+			BackendName: "legacy",
+			BackendType: string(WEBDAV),
+			ConfigAttrs: map[string]interface{}{"url": srv.URL + "/dav/", "path": path, "username": "infra", "password": password},
+		}
+	}
+
+	b, err := NewBackend(context.Background(), config("/team a/terraform.tfstate", "secret"))
+	require.NoError(t, err)
+	assert.Equal(t, WEBDAV, b.BackendType)
+	assert.Equal(t, "054d7292-3d84-0584-4590-24d6f3b17399", b.Data.State.Lineage)
+
+	_, err = NewBackend(context.Background(), config("listing", "secret"))
+	assert.ErrorContains(t, err, "is an HTML page")
+
+	_, err = NewBackend(context.Background(), config("missing.tfstate", "secret"))
+	assert.True(t, IsUnavailableError(err))
+
+	_, err = NewBackend(context.Background(), config("team a/terraform.tfstate", "wrong"))
+	assert.ErrorContains(t, err, "401 Unauthorized")
+
+	_, err = NewBackend(context.Background(), config("team a/", "secret"))
+	assert.ErrorContains(t, err, "is a collection")
+}
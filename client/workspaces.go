@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"golang.org/x/time/rate"
+)
+
+// defaultWorkspaceKeyPrefix is the prefix terraform stores non default workspaces under
+const defaultWorkspaceKeyPrefix = "env:"
+
+// Workspace is a workspace state object discovered next to the configured state
+type Workspace struct {
+	Name         string
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// listWorkspaces lists the workspace states of the bucket, stored by terraform as <prefix>/<workspace>/<key>.
+// The list requests are spaced by limiter, nil doesn't limit them.
+func listWorkspaces(ctx context.Context, svc s3.ListObjectsV2APIClient, bucket, prefix, key string, limiter *rate.Limiter) ([]Workspace, error) {
+	if prefix == "" {
+		prefix = defaultWorkspaceKeyPrefix
+	}
+	prefix = strings.TrimSuffix(prefix, "/") + "/"
+	suffix := "/" + key
+
+	var workspaces []Workspace
+	paginator := s3.NewListObjectsV2Paginator(svc, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			objectKey := aws.ToString(object.Key)
+			name := strings.TrimSuffix(strings.TrimPrefix(objectKey, prefix), suffix)
+			if !strings.HasSuffix(objectKey, suffix) || name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			workspaces = append(workspaces, Workspace{
+				Name:         name,
+				Key:          objectKey,
+				Size:         object.Size,
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+	}
+	return workspaces, nil
+}
+
+// listRateLimiter returns the limiter of list_rate_limit requests per second, nil when unlimited
+func listRateLimiter(requestsPerSecond float64) *rate.Limiter {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(requestsPerSecond), 1)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeListS3 struct {
+	pages [][]string
+	input *s3.ListObjectsV2Input
+	calls []time.Time
+}
+
+func (f *fakeListS3) ListObjectsV2(_ context.Context, input *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.input = input
+	f.calls = append(f.calls, time.Now())
+	i := 0
+	if input.ContinuationToken != nil {
+		i, _ = strconv.Atoi(aws.ToString(input.ContinuationToken))
+	}
+	page := &s3.ListObjectsV2Output{}
+	if i < len(f.pages)-1 {
+		page.IsTruncated = true
+		page.NextContinuationToken = aws.String(strconv.Itoa(i + 1))
+	}
+	if i < len(f.pages) {
+		for _, key := range f.pages[i] {
+			page.Contents = append(page.Contents, types.Object{
+				Key:          aws.String(key),
+				Size:         42,
+				LastModified: aws.Time(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)),
+			})
+		}
+	}
+	return page, nil
+}
+
+func TestListWorkspaces(t *testing.T) {
+	svc := &fakeListS3{pages: [][]string{
+		{"env:/staging/network.tfstate", "env:/staging/other.tfstate"},
+		{"env:/prod/network.tfstate", "env:/prod/nested/network.tfstate", "env://network.tfstate"},
+	}}
+	workspaces, err := listWorkspaces(context.Background(), svc, "states", "", "network.tfstate", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "env:/", aws.ToString(svc.input.Prefix))
+	require.Len(t, workspaces, 2)
+	assert.Equal(t, Workspace{
+		Name:         "staging",
+		Key:          "env:/staging/network.tfstate",
+		Size:         42,
+		LastModified: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC),
+	}, workspaces[0])
+	assert.Equal(t, "prod", workspaces[1].Name)
+
+	_, err = listWorkspaces(context.Background(), svc, "states", "workspaces/", "network.tfstate", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "workspaces/", aws.ToString(svc.input.Prefix))
+}
+
+func TestListWorkspacesRateLimit(t *testing.T) {
+	svc := &fakeListS3{pages: [][]string{{"env:/a/network.tfstate"}, {"env:/b/network.tfstate"}, {"env:/c/network.tfstate"}}}
+	workspaces, err := listWorkspaces(context.Background(), svc, "states", "", "network.tfstate", listRateLimiter(20))
+	require.NoError(t, err)
+	assert.Len(t, workspaces, 3)
+	require.Len(t, svc.calls, 3)
+	assert.GreaterOrEqual(t, svc.calls[2].Sub(svc.calls[0]), 90*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = listWorkspaces(ctx, svc, "states", "", "network.tfstate", listRateLimiter(1))
+	assert.Error(t, err)
+
+	assert.Nil(t, listRateLimiter(0))
+}
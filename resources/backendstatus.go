@@ -0,0 +1,74 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/cloudquery/cq-provider-sdk/provider/schema"
+	"github.com/cloudquery/cq-provider-terraform/client"
+)
+
+func TFBackendStatus() *schema.Table {
+	return &schema.Table{
+		Name:         "tf_backend_status",
+		Description:  "Whether the state of the backend exists and can be read, with status_only it is checked without fetching the state",
+		Resolver:     resolveTerraformBackendStatus,
+		DeleteFilter: client.DeleteBackendFilter,
+		Multiplex:    client.BackendMultiplex,
+		Columns: []schema.Column{
+			{
+				Name:        "backend_name",
+				Description: "Terraform backend name",
+				Type:        schema.TypeString,
+				Resolver:    resolveBackendName,
+			},
+			{
+				Name:        "backend_type",
+				Description: "Terraform backend type",
+				Type:        schema.TypeString,
+				Resolver:    resolveBackendType,
+			},
+			{
+				Name:        "reachable",
+				Description: "False when the storage of the state could not be reached, on network or server errors",
+				Type:        schema.TypeBool,
+			},
+			{
+				Name:        "exists",
+				Description: "True when the state exists and could be read",
+				Type:        schema.TypeBool,
+			},
+			{
+				Name:        "size",
+				Description: "Size of the state in bytes, 0 when unknown",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "last_modified",
+				Description: "Time the state was last modified, when the backend reports it",
+				Type:        schema.TypeTimestamp,
+			},
+			{
+				Name:        "error",
+				Description: "Error of the status check or fetch of the state",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "fetched_at",
+				Description: "Time the status of the state was checked",
+				Type:        schema.TypeTimestamp,
+				Resolver:    resolveFetchedAt,
+			},
+		},
+	}
+}
+
+// ====================================================================================================================
+//                                               Table Resolver Functions
+// ====================================================================================================================
+func resolveTerraformBackendStatus(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	backend := meta.(*client.Client).Backend()
+	if backend.Status != nil {
+		res <- backend.Status
+	}
+	return nil
+}
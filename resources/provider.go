@@ -11,7 +11,12 @@ func Provider() *provider.Provider {
 		Name:      "terraform",
 		Configure: client.Configure,
 		ResourceMap: map[string]*schema.Table{
-			"tf.data": TFData(),
+			"tf.backend_status":         TFBackendStatus(),
+			"tf.data":                   TFData(),
+			"tf.resource_relationships": TFResourceRelationships(),
+			"tf.schema_versions":        TFSchemaVersions(),
+			"tf.state_size":             TFStateSize(),
+			"tf.workspaces":             TFWorkspaces(),
 		},
 		Config: func() provider.Config {
 			return &client.Config{}
@@ -0,0 +1,87 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/cloudquery/cq-provider-sdk/provider/schema"
+	"github.com/cloudquery/cq-provider-terraform/client"
+)
+
+func TFResourceRelationships() *schema.Table {
+	return &schema.Table{
+		Name:        "tf_resource_relationships",
+		Description: "Resource instances related to the instance whose id is the value of one of their relationship_keys attributes, across all backends. Instances sharing a value are only related to the instance owning it, not to each other.",
+		Resolver:    resolveTerraformResourceRelationships,
+		Options: schema.TableCreationOptions{PrimaryKeys: []string{
+			"backend_name", "address", "deposed", "attribute_key", "value", "target_backend_name", "target_address", "target_deposed",
+		}},
+		Columns: []schema.Column{
+			{
+				Name:        "backend_name",
+				Description: "Terraform backend name of the instance",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "address",
+				Description: "Address of the instance, for example: aws_instance.web[0]",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "deposed",
+				Description: "Deposed object key of the instance, empty for current objects",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "resource_type",
+				Description: "Resource type of the instance",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "attribute_key",
+				Description: "Attribute of the instance holding the value, for example: subnet_id",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "value",
+				Description: "Id of the target held by the attribute",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "target_backend_name",
+				Description: "Terraform backend name of the target instance",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "target_address",
+				Description: "Address of the target instance, whose id attribute is the value",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "target_deposed",
+				Description: "Deposed object key of the target instance, empty for current objects",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "target_resource_type",
+				Description: "Resource type of the target instance",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "fetched_at",
+				Description: "Time the state of the least recently fetched backend of the two instances was fetched",
+				Type:        schema.TypeTimestamp,
+			},
+		},
+	}
+}
+
+// ====================================================================================================================
+//                                               Table Resolver Functions
+// ====================================================================================================================
+func resolveTerraformResourceRelationships(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	c := meta.(*client.Client)
+	for _, relationship := range client.Relationships(c.Backends, c.RelationshipKeys) {
+		res <- relationship
+	}
+	return nil
+}
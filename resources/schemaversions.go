@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/cloudquery/cq-provider-sdk/provider/schema"
+	"github.com/cloudquery/cq-provider-terraform/client"
+)
+
+func TFSchemaVersions() *schema.Table {
+	return &schema.Table{
+		Name:        "tf_schema_versions",
+		Description: "Schema versions of the managed resource types across all backends, diverging versions are a sign of inconsistent provider versions",
+		Resolver:    resolveTerraformSchemaVersions,
+		Options:     schema.TableCreationOptions{PrimaryKeys: []string{"resource_type", "provider_source"}},
+		Columns: []schema.Column{
+			{
+				Name:        "resource_type",
+				Description: "Resource type",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "provider_source",
+				Description: "Provider source address, for example: registry.terraform.io/hashicorp/aws",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "schema_versions",
+				Description: "Distinct schema versions the instances of the resource type are stored with",
+				Type:        schema.TypeIntArray,
+			},
+			{
+				Name:        "backend_names",
+				Description: "Backends holding instances of the resource type",
+				Type:        schema.TypeStringArray,
+			},
+			{
+				Name:        "instance_count",
+				Description: "Number of instances of the resource type",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "divergent",
+				Description: "True when the instances are stored with more than one schema version",
+				Type:        schema.TypeBool,
+			},
+			{
+				Name:        "fetched_at",
+				Description: "Time the state of the least recently fetched backend holding the resource type was fetched",
+				Type:        schema.TypeTimestamp,
+			},
+		},
+	}
+}
+
+// ====================================================================================================================
+//                                               Table Resolver Functions
+// ====================================================================================================================
+func resolveTerraformSchemaVersions(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	c := meta.(*client.Client)
+	for _, entry := range client.SchemaVersions(c.Backends) {
+		res <- entry
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/cloudquery/cq-provider-sdk/provider/schema"
+	"github.com/cloudquery/cq-provider-terraform/client"
+)
+
+// stateSize is the row of tf_state_size
+type stateSize struct {
+	RawBytes      int64
+	ResourceCount int64
+	InstanceCount int64
+	Serial        uint64
+}
+
+func TFStateSize() *schema.Table {
+	return &schema.Table{
+		Name:         "tf_state_size",
+		Description:  "Size of the state of the backend, for trending the growth of states over fetches",
+		Resolver:     resolveTerraformStateSize,
+		DeleteFilter: client.DeleteBackendFilter,
+		Multiplex:    client.StateMultiplex,
+		Columns: []schema.Column{
+			{
+				Name:        "backend_name",
+				Description: "Terraform backend name",
+				Type:        schema.TypeString,
+				Resolver:    resolveBackendName,
+			},
+			{
+				Name:        "raw_bytes",
+				Description: "Size of the state document in bytes, as fetched from the backend",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "resource_count",
+				Description: "Number of resources of the state, 0 when only outputs are decoded",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "instance_count",
+				Description: "Number of resource instances of the state, 0 when only outputs are decoded",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "serial",
+				Description: "Incremental number which describes the state version",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "fetched_at",
+				Description: "Time the state of the backend was fetched",
+				Type:        schema.TypeTimestamp,
+				Resolver:    resolveFetchedAt,
+			},
+		},
+	}
+}
+
+// ====================================================================================================================
+//                                               Table Resolver Functions
+// ====================================================================================================================
+func resolveTerraformStateSize(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	data := meta.(*client.Client).Backend().Data
+	size := stateSize{
+		RawBytes:      data.RawBytes,
+		ResourceCount: int64(len(data.State.Resources)),
+		Serial:        data.State.Serial,
+	}
+	for _, resource := range data.State.Resources {
+		size.InstanceCount += int64(len(resource.Instances))
+	}
+	res <- size
+	return nil
+}
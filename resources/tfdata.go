@@ -5,12 +5,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 
 	"github.com/cloudquery/cq-provider-sdk/provider/diag"
 	"github.com/cloudquery/cq-provider-sdk/provider/schema"
 	"github.com/cloudquery/cq-provider-terraform/client"
 )
 
+// terraformOutput is a root module output along with its name
+type terraformOutput struct {
+	Name string
+	client.OutputState
+}
+
+// providerRequirement is a provider configuration of the show -json configuration along with its key
+type providerRequirement struct {
+	Key string
+	client.ProviderConfig
+}
+
 var providerNameRegex = regexp.MustCompile(`^.*\["(?P<Hostname>.*)/(?P<Namespace>.*)/(?P<Type>.*)"\].*?$`)
 
 func TFData() *schema.Table {
@@ -19,7 +32,7 @@ func TFData() *schema.Table {
 		Description:  "Terraform meta data",
 		Resolver:     resolveTerraformMetaData,
 		DeleteFilter: client.DeleteLineageSerialFilter,
-		Multiplex:    client.BackendMultiplex,
+		Multiplex:    client.StateMultiplex,
 		Columns: []schema.Column{
 			{
 				Name:        "backend_type",
@@ -33,6 +46,12 @@ func TFData() *schema.Table {
 				Description: "Terraform backend name",
 				Resolver:    resolveBackendName,
 			},
+			{
+				Name:        "labels",
+				Type:        schema.TypeJSON,
+				Description: "Labels of the backend config",
+				Resolver:    resolveBackendLabels,
+			},
 			{
 				Name:        "version",
 				Type:        schema.TypeBigInt,
@@ -53,13 +72,32 @@ func TFData() *schema.Table {
 				Type:        schema.TypeString,
 				Description: "The \"lineage\" is a unique ID assigned to a state when it is created",
 			},
+			{
+				Name:        "extra",
+				Description: "Top level state fields unknown to the terraform v4 format, for example fields added by OpenTofu",
+				Type:        schema.TypeJSON,
+				Resolver:    resolveStateExtra,
+			},
+			{
+				Name:        "fetched_at",
+				Description: "Time the state of the backend was fetched",
+				Type:        schema.TypeTimestamp,
+				Resolver:    resolveFetchedAt,
+			},
 		},
 		Relations: []*schema.Table{
 			{
 				Name:        "tf_resources",
 				Description: "Terraform resources",
 				Resolver:    resolveTerraformResources,
+				Options:     schema.TableCreationOptions{PrimaryKeys: []string{"unique_id"}},
 				Columns: []schema.Column{
+					{
+						Name:        "unique_id",
+						Description: "Deterministic unique id of the resource, derived from the backend name and resource address",
+						Type:        schema.TypeString,
+						Resolver:    resolveResourceUniqueId,
+					},
 					{
 						Name:        "tf_data_cq_id",
 						Description: "Unique CloudQuery ID of tf_data table (FK)",
@@ -72,6 +110,12 @@ func TFData() *schema.Table {
 						Type:        schema.TypeUUID,
 						Resolver:    schema.ParentIdResolver,
 					},
+					{
+						Name:        "address",
+						Description: "Resource address, for example: module.vpc.aws_subnet.private",
+						Type:        schema.TypeString,
+						Resolver:    resolveResourceAddress,
+					},
 					{
 						Name:        "module",
 						Description: "Resource module if exists",
@@ -104,13 +148,38 @@ func TFData() *schema.Table {
 						Type:        schema.TypeString,
 						Resolver:    resolveProviderName,
 					},
+					{
+						Name:        "provider_source",
+						Description: "Resource provider source address, for example: registry.terraform.io/hashicorp/aws",
+						Type:        schema.TypeString,
+						Resolver:    resolveProviderSource,
+					},
+					{
+						Name:        "provider_alias",
+						Description: "Alias of the resource provider configuration if exists",
+						Type:        schema.TypeString,
+						Resolver:    resolveProviderAlias,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
 				},
 				Relations: []*schema.Table{
 					{
 						Name:        "tf_resource_instances",
 						Description: "Terraform resource instances",
 						Resolver:    resolveTerraformResourceInstances,
+						Options:     schema.TableCreationOptions{PrimaryKeys: []string{"unique_id"}},
 						Columns: []schema.Column{
+							{
+								Name:        "unique_id",
+								Description: "Deterministic unique id of the instance, derived from the backend name, instance address and deposed key",
+								Type:        schema.TypeString,
+								Resolver:    resolveInstanceUniqueId,
+							},
 							{
 								Name:        "tf_resource_cq_id",
 								Description: "Unique CloudQuery ID of tf_resource_instance table (FK)",
@@ -123,6 +192,17 @@ func TFData() *schema.Table {
 								Type:        schema.TypeUUID,
 								Resolver:    schema.ParentIdResolver,
 							},
+							{
+								Name:        "address",
+								Description: "Instance address, for example: aws_subnet.private[0] or aws_iam_user.this[\"admin\"]",
+								Type:        schema.TypeString,
+								Resolver:    resolveInstanceAddress,
+							},
+							{
+								Name:        "deposed",
+								Description: "Deposed object key, set for objects pending destruction after a create_before_destroy replacement",
+								Type:        schema.TypeString,
+							},
 							{
 								Name:        "instance_id",
 								Description: "Instance id",
@@ -140,6 +220,12 @@ func TFData() *schema.Table {
 								Type:        schema.TypeJSON,
 								Resolver:    resolveInstanceAttributes,
 							},
+							{
+								Name:        "attributes_flat",
+								Description: "Original flatmap attributes of instances migrated from legacy (v3) state",
+								Type:        schema.TypeJSON,
+								Resolver:    resolveInstanceAttributesFlat,
+							},
 							{
 								Name:        "dependencies",
 								Description: "Instance dependencies array",
@@ -150,10 +236,351 @@ func TFData() *schema.Table {
 								Description: "Should resource should be created before destroying",
 								Type:        schema.TypeBool,
 							},
+							{
+								Name:        "fetched_at",
+								Description: "Time the state of the backend was fetched",
+								Type:        schema.TypeTimestamp,
+								Resolver:    resolveFetchedAt,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:        "tf_outputs",
+				Description: "Terraform root module outputs",
+				Resolver:    resolveTerraformOutputs,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "name",
+						Description: "Output name",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "value",
+						Description: "Output value",
+						Type:        schema.TypeJSON,
+						Resolver:    resolveOutputValue,
+					},
+					{
+						Name:        "type",
+						Description: "Output value type, for example: \"string\", [\"list\", \"string\"], etc",
+						Type:        schema.TypeJSON,
+						Resolver:    resolveOutputType,
+					},
+					{
+						Name:        "sensitive",
+						Description: "Whether the output is marked sensitive",
+						Type:        schema.TypeBool,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+			},
+			{
+				Name:        "tf_check_results",
+				Description: "Results of check blocks and custom conditions, recorded by terraform 1.3 and later",
+				Resolver:    resolveTerraformCheckResults,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "object_kind",
+						Description: "Kind of the checked configuration object, for example: resource, output, check",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "config_address",
+						Description: "Address of the checked configuration object",
+						Type:        schema.TypeString,
+						Resolver:    schema.PathResolver("ConfigAddr"),
+					},
+					{
+						Name:        "status",
+						Description: "Aggregate status of the checks: pass, fail, error or unknown",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+				Relations: []*schema.Table{
+					{
+						Name:        "tf_check_result_objects",
+						Description: "Check results of the individual objects of a checked configuration object",
+						Resolver:    resolveTerraformCheckResultObjects,
+						Columns: []schema.Column{
+							{
+								Name:        "tf_check_result_cq_id",
+								Description: "Unique CloudQuery ID of tf_check_results table (FK)",
+								Type:        schema.TypeUUID,
+								Resolver:    schema.ParentIdResolver,
+							},
+							{
+								Name:        "object_address",
+								Description: "Address of the checked object",
+								Type:        schema.TypeString,
+								Resolver:    schema.PathResolver("ObjectAddr"),
+							},
+							{
+								Name:        "status",
+								Description: "Status of the checks: pass, fail, error or unknown",
+								Type:        schema.TypeString,
+							},
+							{
+								Name:        "failure_messages",
+								Description: "Error messages of the failed checks",
+								Type:        schema.TypeStringArray,
+							},
+							{
+								Name:        "fetched_at",
+								Description: "Time the state of the backend was fetched",
+								Type:        schema.TypeTimestamp,
+								Resolver:    resolveFetchedAt,
+							},
 						},
 					},
 				},
 			},
+			{
+				Name:        "tf_footprint",
+				Description: "Resource counts of the state by provider source, provider alias and region",
+				Resolver:    resolveTerraformFootprint,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "provider_source",
+						Description: "Provider source address, for example: registry.terraform.io/hashicorp/aws",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "provider_alias",
+						Description: "Alias of the provider configuration if exists",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "region",
+						Description: "Region of the instances, from their region attribute or arn, empty if unknown",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "resource_count",
+						Description: "Number of resources with instances in the group",
+						Type:        schema.TypeBigInt,
+					},
+					{
+						Name:        "instance_count",
+						Description: "Number of resource instances in the group",
+						Type:        schema.TypeBigInt,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+			},
+			{
+				Name:        "tf_requirements",
+				Description: "Provider requirements of the configuration, available when the input is a configuration inclusive `terraform show -json`",
+				Resolver:    resolveTerraformRequirements,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "key",
+						Description: "Provider configuration key, for example: aws, aws.east or module.network:aws",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "name",
+						Description: "Provider local name",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "full_name",
+						Description: "Provider source address, for example: registry.terraform.io/hashicorp/aws",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "alias",
+						Description: "Provider configuration alias if exists",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "module_address",
+						Description: "Address of the module the provider is configured in, empty for the root module",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "version_constraint",
+						Description: "Version constraint of the provider, for example: ~> 4.0",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+			},
+			{
+				Name:        "tf_references",
+				Description: "Attribute level references between the resources of the configuration, available when the input is a configuration inclusive `terraform show -json`",
+				Resolver:    resolveTerraformReferences,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "from_address",
+						Description: "Configuration address of the referring resource, for example: module.network.aws_subnet.private",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "to_address",
+						Description: "Configuration address of the referenced resource",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "attribute",
+						Description: "Attribute path of the referring resource, for example: subnet_id, ebs_block_device[0].kms_key_id, count, for_each or depends_on",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "reference",
+						Description: "Reference expression as written in the configuration, for example: aws_subnet.private[0].id",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+			},
+			{
+				Name:        "tf_resource_attribute_types",
+				Description: "Types of the resource attributes inferred from their values, available when the input is a `terraform show -json` read with attribute_types enabled",
+				Resolver:    resolveTerraformResourceAttributeTypes,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "mode",
+						Description: "Resource mode, for example: data or managed",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "resource_type",
+						Description: "Resource type",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "attribute_path",
+						Description: "Attribute path, list elements are denoted by [*], for example: ebs_block_device[*].volume_size",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "type",
+						Description: "Terraform type of the attribute: string, number, bool, list(<element type>), tuple or object, maps and nested blocks are reported as object",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+			},
+			{
+				Name:        "tf_imports",
+				Description: "Resources being imported by import blocks, available when the input is a `terraform show -json` plan",
+				Resolver:    resolveTerraformImports,
+				Columns: []schema.Column{
+					{
+						Name:        "tf_data_cq_id",
+						Description: "Unique CloudQuery ID of tf_data table (FK)",
+						Type:        schema.TypeUUID,
+						Resolver:    schema.ParentIdResolver,
+					},
+					{
+						Name:        "address",
+						Description: "Import target resource address",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "module_address",
+						Description: "Module address of the import target if exists",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "type",
+						Description: "Resource type",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "name",
+						Description: "Resource name",
+						Type:        schema.TypeString,
+					},
+					{
+						Name:        "id",
+						Description: "Id of the imported remote object",
+						Type:        schema.TypeString,
+						Resolver:    resolveImportId,
+					},
+					{
+						Name:        "actions",
+						Description: "Planned actions of the import target, for example: no-op, update, etc",
+						Type:        schema.TypeStringArray,
+						Resolver:    schema.PathResolver("Change.Actions"),
+					},
+					{
+						Name:        "fetched_at",
+						Description: "Time the state of the backend was fetched",
+						Type:        schema.TypeTimestamp,
+						Resolver:    resolveFetchedAt,
+					},
+				},
+			},
 		},
 	}
 }
@@ -180,6 +607,31 @@ func resolveBackendName(_ context.Context, meta schema.ClientMeta, resource *sch
 	return diag.WrapError(resource.Set("backend_name", backend.BackendName))
 }
 
+func resolveBackendLabels(_ context.Context, meta schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	backend := meta.(*client.Client).Backend()
+	if len(backend.Labels) == 0 {
+		return nil
+	}
+	return diag.WrapError(resource.Set(c.Name, backend.Labels))
+}
+
+func resolveFetchedAt(_ context.Context, meta schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	backend := meta.(*client.Client).Backend()
+	return diag.WrapError(resource.Set(c.Name, backend.FetchedAt))
+}
+
+func resolveStateExtra(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	state := resource.Item.(client.State)
+	if len(state.Extra) == 0 {
+		return nil
+	}
+	extra, err := json.Marshal(state.Extra)
+	if err != nil {
+		return diag.WrapError(err)
+	}
+	return diag.WrapError(resource.Set(c.Name, extra))
+}
+
 func resolveTerraformResources(_ context.Context, _ schema.ClientMeta, parent *schema.Resource, res chan<- interface{}) error {
 	state := parent.Item.(client.State)
 	for _, resource := range state.Resources {
@@ -196,6 +648,128 @@ func resolveTerraformResourceInstances(_ context.Context, _ schema.ClientMeta, p
 	return nil
 }
 
+func resolveTerraformOutputs(_ context.Context, _ schema.ClientMeta, parent *schema.Resource, res chan<- interface{}) error {
+	state := parent.Item.(client.State)
+	for name, output := range state.RootOutputs {
+		res <- terraformOutput{Name: name, OutputState: output}
+	}
+	return nil
+}
+
+func resolveOutputValue(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	output := resource.Item.(terraformOutput)
+	return diag.WrapError(resource.Set(c.Name, []byte(output.ValueRaw)))
+}
+
+func resolveOutputType(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	output := resource.Item.(terraformOutput)
+	if len(output.ValueTypeRaw) == 0 {
+		return nil
+	}
+	return diag.WrapError(resource.Set(c.Name, []byte(output.ValueTypeRaw)))
+}
+
+func resolveTerraformCheckResults(_ context.Context, _ schema.ClientMeta, parent *schema.Resource, res chan<- interface{}) error {
+	state := parent.Item.(client.State)
+	for _, result := range state.CheckResults {
+		res <- result
+	}
+	return nil
+}
+
+func resolveTerraformCheckResultObjects(_ context.Context, _ schema.ClientMeta, parent *schema.Resource, res chan<- interface{}) error {
+	result := parent.Item.(client.CheckResults)
+	for _, object := range result.Objects {
+		res <- object
+	}
+	return nil
+}
+
+func resolveTerraformImports(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	c := meta.(*client.Client)
+	backend := c.Backend()
+	if backend.Data.ShowJSON == nil {
+		return nil
+	}
+	for _, change := range backend.Data.ShowJSON.ResourceChanges {
+		if change.Change.Importing != nil {
+			res <- change
+		}
+	}
+	return nil
+}
+
+func resolveTerraformRequirements(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	backend := meta.(*client.Client).Backend()
+	if backend.Data.ShowJSON == nil || backend.Data.ShowJSON.Configuration == nil {
+		return nil
+	}
+	providers := backend.Data.ShowJSON.Configuration.ProviderConfig
+	keys := make([]string, 0, len(providers))
+	for key := range providers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		res <- providerRequirement{Key: key, ProviderConfig: providers[key]}
+	}
+	return nil
+}
+
+func resolveTerraformReferences(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	backend := meta.(*client.Client).Backend()
+	if backend.Data.ShowJSON == nil || backend.Data.ShowJSON.Configuration == nil {
+		return nil
+	}
+	for _, reference := range client.References(backend.Data.ShowJSON.Configuration) {
+		res <- reference
+	}
+	return nil
+}
+
+func resolveTerraformResourceAttributeTypes(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	backend := meta.(*client.Client).Backend()
+	if backend.Data.ShowJSON == nil {
+		return nil
+	}
+	for _, attributeType := range client.AttributeTypes(backend.Data.ShowJSON) {
+		res <- attributeType
+	}
+	return nil
+}
+
+func resolveImportId(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	change := resource.Item.(client.ResourceChange)
+	if change.Change.Importing.ID == "" {
+		return nil
+	}
+	return diag.WrapError(resource.Set(c.Name, change.Change.Importing.ID))
+}
+
+func resolveResourceUniqueId(_ context.Context, meta schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	backend := meta.(*client.Client).Backend()
+	res := resource.Item.(client.Resource)
+	return diag.WrapError(resource.Set(c.Name, client.UniqueID(backend.BackendName, res.Address(), "")))
+}
+
+func resolveResourceAddress(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	res := resource.Item.(client.Resource)
+	return diag.WrapError(resource.Set(c.Name, res.Address()))
+}
+
+func resolveInstanceUniqueId(_ context.Context, meta schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	backend := meta.(*client.Client).Backend()
+	res := resource.Parent.Item.(client.Resource)
+	instance := resource.Item.(client.Instance)
+	return diag.WrapError(resource.Set(c.Name, client.UniqueID(backend.BackendName, res.InstanceAddress(instance), instance.Deposed)))
+}
+
+func resolveInstanceAddress(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	res := resource.Parent.Item.(client.Resource)
+	instance := resource.Item.(client.Instance)
+	return diag.WrapError(resource.Set(c.Name, res.InstanceAddress(instance)))
+}
+
 func resolveProviderName(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
 	res := resource.Item.(client.Resource)
 	matches := providerNameRegex.FindStringSubmatch(res.ProviderConfig)
@@ -206,6 +780,28 @@ func resolveProviderName(_ context.Context, _ schema.ClientMeta, resource *schem
 	return nil
 }
 
+func resolveProviderSource(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	res := resource.Item.(client.Resource)
+	source, _ := client.ParseProviderConfig(res.ProviderConfig)
+	return diag.WrapError(resource.Set(c.Name, source))
+}
+
+func resolveProviderAlias(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	res := resource.Item.(client.Resource)
+	if _, alias := client.ParseProviderConfig(res.ProviderConfig); alias != "" {
+		return diag.WrapError(resource.Set(c.Name, alias))
+	}
+	return nil
+}
+
+func resolveTerraformFootprint(_ context.Context, _ schema.ClientMeta, parent *schema.Resource, res chan<- interface{}) error {
+	state := parent.Item.(client.State)
+	for _, entry := range client.Footprint(state) {
+		res <- entry
+	}
+	return nil
+}
+
 func resolveInstanceAttributes(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
 	instance := resource.Item.(client.Instance)
 	attrs, err := instance.AttributesRaw.MarshalJSON()
@@ -215,8 +811,27 @@ func resolveInstanceAttributes(_ context.Context, _ schema.ClientMeta, resource
 	return diag.WrapError(resource.Set(c.Name, attrs))
 }
 
+func resolveInstanceAttributesFlat(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
+	instance := resource.Item.(client.Instance)
+	if len(instance.AttributesFlat) == 0 {
+		return nil
+	}
+	attrs, err := json.Marshal(instance.AttributesFlat)
+	if err != nil {
+		return diag.WrapError(err)
+	}
+	return diag.WrapError(resource.Set(c.Name, attrs))
+}
+
 func resolveInstanceInternalId(_ context.Context, _ schema.ClientMeta, resource *schema.Resource, c schema.Column) error {
 	instance := resource.Item.(client.Instance)
+	if len(instance.AttributesRaw) == 0 {
+		// legacy migrated instances only have flatmap attributes
+		if val, ok := instance.AttributesFlat["id"]; ok {
+			return diag.WrapError(resource.Set(c.Name, val))
+		}
+		return nil
+	}
 	data := make(map[string]interface{})
 	if err := json.Unmarshal(instance.AttributesRaw, &data); err != nil {
 		return diag.WrapError(fmt.Errorf("could not parse internal instance id"))
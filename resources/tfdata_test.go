@@ -1,9 +1,18 @@
 package resources
 
 import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/cloudquery/cq-provider-sdk/provider/schema"
 	providertest "github.com/cloudquery/cq-provider-sdk/provider/testing"
+	"github.com/cloudquery/cq-provider-terraform/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTfData(t *testing.T) {
@@ -19,3 +28,55 @@ func TestTfData(t *testing.T) {
 		Config:   cfg,
 	})
 }
+
+func TestInstanceAttributesFlat(t *testing.T) {
+	// instances of v3 states upgraded without a refresh only carry flatmap attributes
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "version": 4,
+  "terraform_version": "0.12.31",
+  "serial": 3,
+  "lineage": "0f5e1a3c-7f2b-4d8e-9b6a-2c4d1e8f0a7b",
+  "outputs": {},
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "legacy",
+      "provider": "provider.aws",
+      "instances": [
+        {
+          "schema_version": 1,
+          "attributes_flat": {"id": "i-0123456789abcdef0", "ami": "ami-12345678", "tags.%": "1", "tags.Name": "legacy"}
+        }
+      ]
+    }
+  ]
+}`), 0o600))
+
+	backend, err := client.NewBackend(context.Background(), &client.BackendConfigBlock{
+		BackendName: "legacy",
+		BackendType: string(client.LOCAL),
+		ConfigAttrs: map[string]interface{}{"path": path},
+	})
+	require.NoError(t, err)
+	require.Len(t, backend.Data.State.Resources, 1)
+	require.Len(t, backend.Data.State.Resources[0].Instances, 1)
+	instance := backend.Data.State.Resources[0].Instances[0]
+	assert.Empty(t, instance.AttributesRaw)
+	assert.Equal(t, "i-0123456789abcdef0", instance.AttributesFlat["id"])
+
+	instances := TFData().Relations[0].Relations[0]
+	require.Equal(t, "tf_resource_instances", instances.Name)
+	resource := schema.NewResourceData(schema.PostgresDialect{}, instances, nil, instance, nil, time.Now())
+	for _, c := range instances.Columns {
+		switch c.Name {
+		case "instance_id", "attributes_flat":
+			require.NoError(t, c.Resolver(context.Background(), nil, resource, c))
+		}
+	}
+	assert.Equal(t, "i-0123456789abcdef0", resource.Get("instance_id"))
+	var flat map[string]string
+	require.NoError(t, json.Unmarshal(resource.Get("attributes_flat").([]byte), &flat))
+	assert.Equal(t, instance.AttributesFlat, flat)
+}
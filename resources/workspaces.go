@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"context"
+
+	"github.com/cloudquery/cq-provider-sdk/provider/schema"
+	"github.com/cloudquery/cq-provider-terraform/client"
+)
+
+func TFWorkspaces() *schema.Table {
+	return &schema.Table{
+		Name:         "tf_workspaces",
+		Description:  "Workspace states discovered by listing the backend, available for s3 backends with list_workspaces enabled",
+		Resolver:     resolveTerraformWorkspaces,
+		DeleteFilter: client.DeleteBackendFilter,
+		Multiplex:    client.BackendMultiplex,
+		Columns: []schema.Column{
+			{
+				Name:        "backend_name",
+				Description: "Terraform backend name",
+				Type:        schema.TypeString,
+				Resolver:    resolveBackendName,
+			},
+			{
+				Name:        "name",
+				Description: "Workspace name",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "key",
+				Description: "Key of the workspace state object",
+				Type:        schema.TypeString,
+			},
+			{
+				Name:        "size",
+				Description: "Size of the workspace state object in bytes",
+				Type:        schema.TypeBigInt,
+			},
+			{
+				Name:        "last_modified",
+				Description: "Time the workspace state object was last modified",
+				Type:        schema.TypeTimestamp,
+			},
+			{
+				Name:        "fetched_at",
+				Description: "Time the workspaces of the backend were listed",
+				Type:        schema.TypeTimestamp,
+				Resolver:    resolveFetchedAt,
+			},
+		},
+	}
+}
+
+// ====================================================================================================================
+//                                               Table Resolver Functions
+// ====================================================================================================================
+func resolveTerraformWorkspaces(_ context.Context, meta schema.ClientMeta, _ *schema.Resource, res chan<- interface{}) error {
+	c := meta.(*client.Client)
+	backend := c.Backend()
+	for _, workspace := range backend.Workspaces {
+		res <- workspace
+	}
+	return nil
+}